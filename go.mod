@@ -8,6 +8,7 @@ require (
 	github.com/chzyer/readline v1.5.1
 	github.com/google/go-cmp v0.5.9
 	github.com/macrat/ayd v0.16.5
+	github.com/mailru/easyjson v0.7.7
 	github.com/spf13/pflag v1.0.5
 	github.com/yuin/gopher-lua v1.1.0
 	golang.org/x/image v0.7.0
@@ -20,7 +21,6 @@ require (
 	github.com/gobwas/ws v1.2.0 // indirect
 	github.com/goccy/go-json v0.10.2 // indirect
 	github.com/josharian/intern v1.0.0 // indirect
-	github.com/mailru/easyjson v0.7.7 // indirect
 	golang.org/x/sys v0.7.0 // indirect
 	golang.org/x/text v0.9.0 // indirect
 )
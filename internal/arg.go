@@ -17,8 +17,14 @@ type Arg struct {
 	Debug     bool
 	Head      bool
 	Recording bool
+
+	// StepTimeout is the default timeout to wait for elements in each step.
+	StepTimeout time.Duration
 }
 
+// DefaultStepTimeout is the default value of Arg.StepTimeout.
+const DefaultStepTimeout = 5 * time.Second
+
 func (a Arg) ArtifactDir(basedir string) string {
 	if a.Mode == "repl" || a.Mode == "stdin" {
 		return filepath.Join(basedir, "out")
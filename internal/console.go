@@ -0,0 +1,87 @@
+package webscenario
+
+import (
+	"encoding/json"
+	"strings"
+
+	"github.com/chromedp/cdproto/runtime"
+	"github.com/yuin/gopher-lua"
+)
+
+// unpackRemoteObject converts a RemoteObject to a Go value and a string representation.
+func unpackRemoteObject(obj *runtime.RemoteObject) (any, string) {
+	if len(obj.Value) > 0 {
+		var v any
+		if err := json.Unmarshal(obj.Value, &v); err == nil {
+			if s, ok := v.(string); ok {
+				return s, s
+			}
+			return v, string(obj.Value)
+		}
+	}
+	if obj.UnserializableValue != "" {
+		return obj.UnserializableValue.String(), obj.UnserializableValue.String()
+	}
+	if obj.Description != "" {
+		return obj.Description, obj.Description
+	}
+	return nil, obj.Type.String()
+}
+
+// setStackTop sets url, line, and column of the top of the stack trace to the table.
+func setStackTop(L *lua.LState, tbl *lua.LTable, st *runtime.StackTrace) {
+	if st == nil || len(st.CallFrames) == 0 {
+		return
+	}
+	f := st.CallFrames[0]
+	L.SetField(tbl, "url", lua.LString(f.URL))
+	L.SetField(tbl, "line", lua.LNumber(f.LineNumber+1))
+	L.SetField(tbl, "column", lua.LNumber(f.ColumnNumber+1))
+}
+
+func PackConsoleEvent(L *lua.LState, tbl *lua.LTable, e *runtime.EventConsoleAPICalled) {
+	args := L.NewTable()
+	msgs := make([]string, len(e.Args))
+	for i, a := range e.Args {
+		v, s := unpackRemoteObject(a)
+		args.Append(PackLValue(L, v))
+		msgs[i] = s
+	}
+
+	L.SetField(tbl, "type", lua.LString(e.Type.String()))
+	L.SetField(tbl, "message", lua.LString(strings.Join(msgs, " ")))
+	L.SetField(tbl, "args", args)
+	if e.Timestamp != nil {
+		L.SetField(tbl, "timestamp", lua.LNumber(e.Timestamp.Time().UnixMilli()))
+	}
+	setStackTop(L, tbl, e.StackTrace)
+}
+
+// ExceptionMessage makes a human readable message of an exception, without stack trace.
+func ExceptionMessage(d *runtime.ExceptionDetails) string {
+	if d.Exception == nil {
+		return d.Text
+	}
+	if d.Exception.Description != "" {
+		msg, _, _ := strings.Cut(d.Exception.Description, "\n")
+		return msg
+	}
+	_, s := unpackRemoteObject(d.Exception)
+	return d.Text + " " + s
+}
+
+func PackExceptionEvent(L *lua.LState, tbl *lua.LTable, e *runtime.EventExceptionThrown) {
+	d := e.ExceptionDetails
+
+	L.SetField(tbl, "message", lua.LString(ExceptionMessage(d)))
+	if d.Exception != nil && d.Exception.Description != "" {
+		L.SetField(tbl, "stack", lua.LString(d.Exception.Description))
+	}
+	if e.Timestamp != nil {
+		L.SetField(tbl, "timestamp", lua.LNumber(e.Timestamp.Time().UnixMilli()))
+	}
+	L.SetField(tbl, "url", lua.LString(d.URL))
+	L.SetField(tbl, "line", lua.LNumber(d.LineNumber+1))
+	L.SetField(tbl, "column", lua.LNumber(d.ColumnNumber+1))
+	setStackTop(L, tbl, d.StackTrace)
+}
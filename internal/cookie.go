@@ -0,0 +1,124 @@
+package webscenario
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/chromedp/cdproto/cdp"
+	"github.com/chromedp/cdproto/network"
+	"github.com/yuin/gopher-lua"
+)
+
+func PackCookie(L *lua.LState, c *http.Cookie) *lua.LTable {
+	tbl := L.NewTable()
+	L.SetField(tbl, "name", lua.LString(c.Name))
+	L.SetField(tbl, "value", lua.LString(c.Value))
+	L.SetField(tbl, "path", lua.LString(c.Path))
+	L.SetField(tbl, "domain", lua.LString(c.Domain))
+	if !c.Expires.IsZero() {
+		L.SetField(tbl, "expires", lua.LNumber(c.Expires.UnixMilli()))
+	}
+	L.SetField(tbl, "secure", lua.LBool(c.Secure))
+	L.SetField(tbl, "httponly", lua.LBool(c.HttpOnly))
+
+	L.SetField(tbl, "samesite", lua.LString(formatSameSite(c.SameSite)))
+
+	return tbl
+}
+
+func formatSameSite(s http.SameSite) string {
+	switch s {
+	case http.SameSiteDefaultMode:
+		return "default"
+	case http.SameSiteLaxMode:
+		return "lax"
+	case http.SameSiteStrictMode:
+		return "strict"
+	case http.SameSiteNoneMode:
+		return "none"
+	default:
+		return ""
+	}
+}
+
+func parseSameSite(s string) http.SameSite {
+	switch strings.ToLower(s) {
+	case "default":
+		return http.SameSiteDefaultMode
+	case "lax":
+		return http.SameSiteLaxMode
+	case "strict":
+		return http.SameSiteStrictMode
+	case "none":
+		return http.SameSiteNoneMode
+	default:
+		return 0
+	}
+}
+
+func CookieFromCDP(c *network.Cookie) *http.Cookie {
+	h := &http.Cookie{
+		Name:     c.Name,
+		Value:    c.Value,
+		Path:     c.Path,
+		Domain:   c.Domain,
+		Secure:   c.Secure,
+		HttpOnly: c.HTTPOnly,
+	}
+	if !c.Session {
+		h.Expires = time.UnixMilli(int64(c.Expires * 1000))
+	}
+	switch c.SameSite {
+	case network.CookieSameSiteLax:
+		h.SameSite = http.SameSiteLaxMode
+	case network.CookieSameSiteStrict:
+		h.SameSite = http.SameSiteStrictMode
+	case network.CookieSameSiteNone:
+		h.SameSite = http.SameSiteNoneMode
+	}
+	return h
+}
+
+// UnpackCookieParam makes a parameter for network.SetCookies from a Lua table that has the same format as PackCookie's one.
+// The table can have the url field to determine domain and path instead of domain and path fields.
+func UnpackCookieParam(L *lua.LState, tbl *lua.LTable) (*network.CookieParam, error) {
+	name, ok := L.GetField(tbl, "name").(lua.LString)
+	if !ok || name == "" {
+		return nil, errors.New("name field is expected be a string.")
+	}
+
+	p := &network.CookieParam{
+		Name:     string(name),
+		Value:    lua.LVAsString(L.GetField(tbl, "value")),
+		URL:      lua.LVAsString(L.GetField(tbl, "url")),
+		Domain:   lua.LVAsString(L.GetField(tbl, "domain")),
+		Path:     lua.LVAsString(L.GetField(tbl, "path")),
+		Secure:   lua.LVAsBool(L.GetField(tbl, "secure")),
+		HTTPOnly: lua.LVAsBool(L.GetField(tbl, "httponly")),
+	}
+
+	switch e := L.GetField(tbl, "expires").(type) {
+	case *lua.LNilType:
+	case lua.LNumber:
+		t := cdp.TimeSinceEpoch(time.UnixMilli(int64(e)))
+		p.Expires = &t
+	default:
+		return nil, errors.New("expires field is expected be a number.")
+	}
+
+	switch strings.ToLower(lua.LVAsString(L.GetField(tbl, "samesite"))) {
+	case "", "default":
+	case "lax":
+		p.SameSite = network.CookieSameSiteLax
+	case "strict":
+		p.SameSite = network.CookieSameSiteStrict
+	case "none":
+		p.SameSite = network.CookieSameSiteNone
+	default:
+		return nil, errors.New(`samesite field is expected be "default", "lax", "strict", or "none".`)
+	}
+
+	return p, nil
+}
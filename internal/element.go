@@ -2,22 +2,98 @@ package webscenario
 
 import (
 	"context"
+	"encoding/json"
+	"errors"
 	"fmt"
 	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
 
 	"github.com/chromedp/cdproto/cdp"
+	"github.com/chromedp/cdproto/dom"
 	"github.com/chromedp/cdproto/input"
 	"github.com/chromedp/cdproto/runtime"
 	"github.com/chromedp/chromedp"
 	"github.com/yuin/gopher-lua"
 )
 
+// ErrDetached is reported when an element has been removed from the document, and it can't be found again by the original query.
+var ErrDetached = errors.New("element is detached")
+
+// elementRef holds the node of an element, and the way to find the node again.
+// It is shared between copies of an Element, so that a re-resolved node is used by all of them.
+type elementRef struct {
+	sync.Mutex
+
+	node   *cdp.Node
+	locate func(ctx context.Context) (*cdp.Node, error)
+}
+
+// isAttached checks if the node is still valid and connected to the document.
+func isAttached(ctx context.Context, node *cdp.Node) (bool, error) {
+	var ok bool
+	if err := callOnNode(node, `function() { return this.isConnected }`, &ok).Do(ctx); err != nil {
+		if ctx.Err() != nil {
+			return false, ctx.Err()
+		}
+		// The node ID is invalidated, because the node was removed or the document was updated.
+		return false, nil
+	}
+	return ok, nil
+}
+
+// Current returns the last known node, without checking if it is still attached.
+func (r *elementRef) Current() *cdp.Node {
+	r.Lock()
+	defer r.Unlock()
+	return r.node
+}
+
+// Get returns the node if it is still attached, or finds it again by the original query within `timeout`.
+func (r *elementRef) Get(ctx context.Context, timeout time.Duration) (*cdp.Node, error) {
+	r.Lock()
+	defer r.Unlock()
+
+	if ok, err := isAttached(ctx, r.node); err != nil {
+		return nil, err
+	} else if ok {
+		return r.node, nil
+	}
+
+	if r.locate == nil {
+		return nil, ErrDetached
+	}
+
+	lctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	node, err := r.locate(lctx)
+	if errors.Is(ctx.Err(), context.Canceled) {
+		return nil, ctx.Err()
+	} else if err != nil || node == nil {
+		return nil, ErrDetached
+	}
+	r.node = node
+	return node, nil
+}
+
 type Element struct {
 	name string
-	node *cdp.Node
+	ref  *elementRef
 	tab  *Tab
 }
 
+// newElement makes an Element of the node.
+// The `locate` is used to find the node again after the node is detached, for example by re-rendering of the page. It can be nil if the node can't be found again.
+func newElement(t *Tab, name string, node *cdp.Node, locate func(context.Context) (*cdp.Node, error)) Element {
+	return Element{
+		name: name,
+		ref:  &elementRef{node: node, locate: locate},
+		tab:  t,
+	}
+}
+
 func nodeAction(sel interface{}, node **cdp.Node, opts ...chromedp.QueryOption) chromedp.QueryAction {
 	return chromedp.QueryAfter(sel, func(ctx context.Context, id runtime.ExecutionContextID, nodes ...*cdp.Node) error {
 		*node = nodes[0]
@@ -25,16 +101,139 @@ func nodeAction(sel interface{}, node **cdp.Node, opts ...chromedp.QueryOption)
 	}, opts...)
 }
 
+// locateNode makes a function to find the first node that matches to the query.
+func locateNode(query string, opts ...chromedp.QueryOption) func(context.Context) (*cdp.Node, error) {
+	return func(ctx context.Context) (node *cdp.Node, err error) {
+		err = nodeAction(query, &node, opts...).Do(ctx)
+		return node, err
+	}
+}
+
+// fingerprintScript returns a short hash of the HTML of each node in the arguments.
+const fingerprintScript = `function(...nodes) {
+	return nodes.map((n) => {
+		const s = n.outerHTML ?? n.textContent ?? '';
+		let h = 0x811c9dc5;
+		for (let i = 0; i < s.length; i++) {
+			h = Math.imul(h ^ s.charCodeAt(i), 0x01000193);
+		}
+		return (h >>> 0).toString(36) + ':' + s.length;
+	});
+}`
+
+// fingerprintGroup is a counter to make unique object group names for fingerprints.
+var fingerprintGroup atomic.Uint64
+
+// fingerprints returns a hash of HTML of each node, to identify the nodes after the page is re-rendered.
+// The hashes are calculated in a single function call in the browser, to avoid transferring whole HTML of each node.
+func fingerprints(ctx context.Context, nodes []*cdp.Node) ([]string, error) {
+	if len(nodes) == 0 {
+		return []string{}, nil
+	}
+
+	group := fmt.Sprintf("fingerprints-%d", fingerprintGroup.Add(1))
+	defer runtime.ReleaseObjectGroup(group).Do(ctx)
+
+	args := make([]*runtime.CallArgument, len(nodes))
+	for i, n := range nodes {
+		obj, err := dom.ResolveNode().WithNodeID(n.NodeID).WithObjectGroup(group).Do(ctx)
+		if err != nil {
+			return nil, err
+		}
+		args[i] = &runtime.CallArgument{ObjectID: obj.ObjectID}
+	}
+
+	res, exp, err := runtime.CallFunctionOn(fingerprintScript).
+		WithObjectID(args[0].ObjectID).
+		WithArguments(args).
+		WithReturnByValue(true).
+		Do(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if exp != nil {
+		return nil, exp
+	}
+
+	var fs []string
+	if err := json.Unmarshal(res.Value, &fs); err != nil {
+		return nil, err
+	}
+	return fs, nil
+}
+
+// matchFingerprint finds the index in `current` of the i-th node in `orig`.
+// It returns -1 if the node is not found.
+// The `ambiguous` is true if the node can't be distinguished from other nodes that have the same fingerprint.
+func matchFingerprint(orig, current []string, i int) (index int, ambiguous bool) {
+	same := len(orig) == len(current)
+	for j := 0; same && j < len(orig); j++ {
+		same = orig[j] == current[j]
+	}
+	if same {
+		return i, false
+	}
+
+	found := -1
+	for j, f := range current {
+		if f == orig[i] {
+			if found >= 0 {
+				return -1, true
+			}
+			found = j
+		}
+	}
+	return found, false
+}
+
+// locateInList makes a function to find the i-th node in the result of `all` again.
+// The node is identified by its fingerprint, so that it doesn't pick another node if the list has been changed.
+// It raises ErrDetached immediately if the list has other nodes but none of them has the same fingerprint, because it means the content has been changed.
+// Otherwise, for example the list is empty while re-rendering, it waits until the node is found, or raises ErrDetached if it isn't found until the timeout.
+func locateInList(all func(context.Context) ([]*cdp.Node, error), i int, orig []string) func(context.Context) (*cdp.Node, error) {
+	return func(ctx context.Context) (*cdp.Node, error) {
+		for {
+			nodes, err := all(ctx)
+			if err != nil {
+				return nil, err
+			}
+			fs, err := fingerprints(ctx, nodes)
+			if err != nil {
+				return nil, err
+			}
+
+			j, ambiguous := matchFingerprint(orig, fs, i)
+			if j >= 0 {
+				return nodes[j], nil
+			}
+			if !ambiguous && len(nodes) > 0 {
+				return nil, ErrDetached
+			}
+
+			select {
+			case <-ctx.Done():
+				return nil, ErrDetached
+			case <-time.After(50 * time.Millisecond):
+			}
+		}
+	}
+}
+
 func NewElement(L *lua.LState, t *Tab, query string) Element {
+	return NewElementBy(L, t, fmt.Sprintf("$(%q)", strings.TrimSpace(query)), query, byQuery(query, false))
+}
+
+// NewElementBy finds an element by query option `by`, and makes an Element named `name`.
+func NewElementBy(L *lua.LState, t *Tab, name, query string, by chromedp.QueryOption) Element {
+	locate := locateNode(query, by)
+
 	var node *cdp.Node
-	name := fmt.Sprintf("$(%q)", strings.TrimSpace(query))
-	t.RunSelector(L, name, nodeAction(query, &node, chromedp.ByQuery))
+	t.RunSelector(L, name, chromedp.ActionFunc(func(ctx context.Context) (err error) {
+		node, err = locate(ctx)
+		return err
+	}))
 
-	return Element{
-		name: name,
-		node: node,
-		tab:  t,
-	}
+	return newElement(t, name, node, locate)
 }
 
 func (e Element) ToLua(L *lua.LState) *lua.LUserData {
@@ -44,14 +243,22 @@ func (e Element) ToLua(L *lua.LState) *lua.LUserData {
 	return ud
 }
 
-func newElementsTableFromNodes(L *lua.LState, t *Tab, name string, nodes []*cdp.Node) *lua.LTable {
+// newElementsTable finds nodes by `all`, and makes a table of Elements.
+// Each element is found again from the result of `all` after it is detached, if the same content is still in the list.
+func newElementsTable(L *lua.LState, t *Tab, name string, all func(context.Context) ([]*cdp.Node, error)) *lua.LTable {
+	var nodes []*cdp.Node
+	var fs []string
+	t.RunSelector(L, name, chromedp.ActionFunc(func(ctx context.Context) (err error) {
+		if nodes, err = all(ctx); err != nil {
+			return err
+		}
+		fs, err = fingerprints(ctx, nodes)
+		return err
+	}))
+
 	tbl := L.NewTable()
-	for _, node := range nodes {
-		tbl.Append(Element{
-			name: name,
-			node: node,
-			tab:  t,
-		}.ToLua(L))
+	for i, node := range nodes {
+		tbl.Append(newElement(t, name, node, locateInList(all, i, fs)).ToLua(L))
 	}
 
 	idx := 1
@@ -70,22 +277,22 @@ func newElementsTableFromNodes(L *lua.LState, t *Tab, name string, nodes []*cdp.
 	return tbl
 }
 
+// locateNodes makes a function to find all nodes that match to the query.
+func locateNodes(query string, opts ...chromedp.QueryOption) func(context.Context) ([]*cdp.Node, error) {
+	return func(ctx context.Context) (nodes []*cdp.Node, err error) {
+		err = chromedp.Nodes(query, &nodes, opts...).Do(ctx)
+		return nodes, err
+	}
+}
+
 func NewElementsTable(L *lua.LState, t *Tab, query string) *lua.LTable {
-	var nodes []*cdp.Node
 	name := fmt.Sprintf("$:all(%q)", strings.TrimSpace(query))
-	t.RunSelector(
-		L,
-		name,
-		chromedp.Nodes(query, &nodes, chromedp.ByQueryAll, chromedp.AtLeast(0)),
-	)
-	return newElementsTableFromNodes(L, t, query, nodes)
+	return newElementsTable(L, t, name, locateNodes(query, byQuery(query, true), chromedp.AtLeast(0)))
 }
 
 func NewElementsTableByXPath(L *lua.LState, t *Tab, query string) *lua.LTable {
-	var nodes []*cdp.Node
 	name := fmt.Sprintf("$:xpath(%q)", strings.TrimSpace(query))
-	t.RunSelector(L, name, chromedp.Nodes(query, &nodes, chromedp.BySearch))
-	return newElementsTableFromNodes(L, t, name, nodes)
+	return newElementsTable(L, t, name, locateNodes(query, chromedp.BySearch))
 }
 
 func CheckElement(L *lua.LState) Element {
@@ -99,47 +306,224 @@ func CheckElement(L *lua.LState) Element {
 	return Element{}
 }
 
-func (e Element) ids() []cdp.NodeID {
-	return []cdp.NodeID{e.node.NodeID}
+// node returns the up-to-date node of the element, finding it again if the node has been detached.
+func (e Element) node(ctx context.Context) (*cdp.Node, error) {
+	return e.ref.Get(ctx, e.tab.timeout)
+}
+
+// on makes an action that made by `f` with the up-to-date node of the element when it runs.
+func (e Element) on(f func(node *cdp.Node) chromedp.Action) chromedp.ActionFunc {
+	return func(ctx context.Context) error {
+		node, err := e.node(ctx)
+		if err != nil {
+			return err
+		}
+		return f(node).Do(ctx)
+	}
+}
+
+// byNodeID is a shorthand to call chromedp's query functions that take node IDs, with the up-to-date node of the element.
+func (e Element) byNodeID(f func(ids []cdp.NodeID) chromedp.Action) chromedp.ActionFunc {
+	return e.on(func(node *cdp.Node) chromedp.Action {
+		return f([]cdp.NodeID{node.NodeID})
+	})
+}
+
+// callOn makes an action to call a JavaScript function with `this` as the node that resolved by `resolve`.
+func callOn(resolve *dom.ResolveNodeParams, function string, res any, args ...any) chromedp.ActionFunc {
+	return func(ctx context.Context) error {
+		r, err := resolve.Do(ctx)
+		if err != nil {
+			return err
+		}
+		defer runtime.ReleaseObject(r.ObjectID).Do(ctx)
+
+		return chromedp.CallFunctionOn(function, res, func(p *runtime.CallFunctionOnParams) *runtime.CallFunctionOnParams {
+			return p.WithObjectID(r.ObjectID).WithAwaitPromise(true)
+		}, args...).Do(ctx)
+	}
+}
+
+// callOnNode makes an action to call a JavaScript function with `this` as the node.
+func callOnNode(node *cdp.Node, function string, res any, args ...any) chromedp.ActionFunc {
+	return callOn(dom.ResolveNode().WithNodeID(node.NodeID), function, res, args...)
+}
+
+// requestNodes converts a JavaScript array of nodes into node IDs, and releases the array.
+func requestNodes(ctx context.Context, arr *runtime.RemoteObject) ([]cdp.NodeID, error) {
+	if arr == nil || arr.ObjectID == "" {
+		return nil, nil
+	}
+	defer runtime.ReleaseObject(arr.ObjectID).Do(ctx)
+
+	props, _, _, _, err := runtime.GetProperties(arr.ObjectID).WithOwnProperties(true).Do(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	ids := make([]cdp.NodeID, 0, len(props))
+	for _, p := range props {
+		if p.Value == nil || p.Value.Subtype != runtime.SubtypeNode {
+			continue
+		}
+		id, err := dom.RequestNode(p.Value.ObjectID).Do(ctx)
+		if err != nil {
+			return nil, err
+		}
+		ids = append(ids, id)
+	}
+	return ids, nil
+}
+
+// byJS is a query option to find nodes by a JavaScript function that returns an array of nodes.
+// The function is called with `this` as the node to start searching, and `args` as arguments.
+func byJS(function string, args ...any) chromedp.QueryOption {
+	return chromedp.ByFunc(func(ctx context.Context, n *cdp.Node) ([]cdp.NodeID, error) {
+		var arr *runtime.RemoteObject
+		if err := callOnNode(n, function, &arr, args...).Do(ctx); err != nil {
+			return nil, err
+		}
+		return requestNodes(ctx, arr)
+	})
+}
+
+// pierceScript selects elements by CSS selector that can contain ">>>" combinator.
+// The "a >>> b" selects elements that match to "b" in the shadow roots of elements that match to "a".
+const pierceScript = `function(query, all) {
+	let found = [this];
+	query.split('>>>').forEach((q, i) => {
+		if (i > 0) {
+			found = found.map(e => e.shadowRoot).filter(r => r);
+		}
+		found = [...new Set(found.flatMap(r => Array.from(r.querySelectorAll(q.trim()))))];
+	});
+	return all ? found : found.slice(0, 1);
+}`
+
+// byQuery is a query option to select elements by CSS selector, that supports ">>>" combinator to pierce shadow roots.
+func byQuery(query string, all bool) chromedp.QueryOption {
+	if strings.Contains(query, ">>>") {
+		return byJS(pierceScript, query, all)
+	}
+	if all {
+		return chromedp.ByQueryAll
+	}
+	return chromedp.ByQuery
+}
+
+// actionabilityScript checks a condition of the element, and returns the name of the condition if it is not met.
+// It returns "attached" instead if the element has been removed from the document.
+const actionabilityScript = `async function(condition) {
+	if (!this.isConnected) {
+		return 'attached';
+	}
+	if (this.nodeType !== Node.ELEMENT_NODE) {
+		return '';
+	}
+	const rect = () => {
+		const r = this.getBoundingClientRect();
+		return [r.x, r.y, r.width, r.height].join(',');
+	};
+	switch (condition) {
+	case 'visible': {
+		const r = this.getBoundingClientRect();
+		const style = getComputedStyle(this);
+		if (r.width <= 0 || r.height <= 0 || style.visibility === 'hidden' || style.display === 'none') {
+			return condition;
+		}
+		break;
+	}
+	case 'stable': {
+		const before = rect();
+		// Animation frames are paused in background pages, so it compares with the position after a while in that case.
+		await new Promise((resolve) => {
+			requestAnimationFrame(() => requestAnimationFrame(resolve));
+			setTimeout(resolve, 100);
+		});
+		if (rect() !== before) {
+			return condition;
+		}
+		break;
+	}
+	case 'enabled':
+		if (this.matches(':disabled') || this.closest('[aria-disabled=true]')) {
+			return condition;
+		}
+		break;
+	}
+	return '';
+}`
+
+// waitActionable makes an action that waits until the element meets all conditions, "visible", "stable", and/or "enabled".
+// It reports which condition is not met if the step timeout of the tab exceeded.
+func (e Element) waitActionable(conditions ...string) chromedp.ActionFunc {
+	return func(ctx context.Context) error {
+		ctx, cancel := context.WithTimeout(ctx, e.tab.timeout)
+		defer cancel()
+
+		for {
+			var unmet string
+			// Conditions are checked one by one, to know which condition was being checked when the timeout exceeded.
+			for _, c := range conditions {
+				err := e.on(func(node *cdp.Node) chromedp.Action {
+					return callOnNode(node, actionabilityScript, &unmet, c)
+				}).Do(ctx)
+				if err != nil {
+					if ctx.Err() != nil && !errors.Is(err, ErrDetached) {
+						return fmt.Errorf("element is not %s within %s", c, e.tab.timeout)
+					}
+					return err
+				}
+				if unmet != "" {
+					break
+				}
+			}
+			if unmet == "" {
+				return nil
+			}
+
+			select {
+			case <-ctx.Done():
+				if unmet == "attached" {
+					return ErrDetached
+				}
+				return fmt.Errorf("element is not %s within %s", unmet, e.tab.timeout)
+			case <-time.After(50 * time.Millisecond):
+			}
+		}
+	}
 }
 
 func (e Element) Select(L *lua.LState, query string) Element {
 	name := fmt.Sprintf("%s(%q)", e.name, strings.TrimSpace(query))
 
+	locate := func(ctx context.Context) (*cdp.Node, error) {
+		parent, err := e.node(ctx)
+		if err != nil {
+			return nil, err
+		}
+		return locateNode(query, byQuery(query, false), chromedp.FromNode(parent))(ctx)
+	}
+
 	var node *cdp.Node
-	e.tab.Run(
-		L,
-		name,
-		false,
-		0,
-		nodeAction(query, &node, chromedp.ByQuery, chromedp.FromNode(e.node)),
-	)
+	e.tab.RunSelector(L, name, chromedp.ActionFunc(func(ctx context.Context) (err error) {
+		node, err = locate(ctx)
+		return err
+	}))
 
-	return Element{
-		name: name,
-		node: node,
-		tab:  e.tab,
-	}
+	return newElement(e.tab, name, node, locate)
 }
 
 func (e Element) SelectAll(L *lua.LState, query string) *lua.LTable {
 	name := fmt.Sprintf("%s:all(%q)", e.name, strings.TrimSpace(query))
 
-	var nodes []*cdp.Node
-
-	e.tab.RunSelector(
-		L,
-		name,
-		chromedp.Nodes(
-			query,
-			&nodes,
-			chromedp.ByQueryAll,
-			chromedp.FromNode(e.node),
-			chromedp.AtLeast(0),
-		),
-	)
-
-	return newElementsTableFromNodes(L, e.tab, name, nodes)
+	return newElementsTable(L, e.tab, name, func(ctx context.Context) ([]*cdp.Node, error) {
+		parent, err := e.node(ctx)
+		if err != nil {
+			return nil, err
+		}
+		return locateNodes(query, byQuery(query, true), chromedp.FromNode(parent), chromedp.AtLeast(0))(ctx)
+	})
 }
 
 func (e Element) SendKeys(L *lua.LState) {
@@ -173,38 +557,308 @@ func (e Element) SendKeys(L *lua.LState) {
 		fmt.Sprintf("%s:sendKeys(%q)", e.name, text),
 		true,
 		0,
-		chromedp.KeyEventNode(e.node, text, chromedp.KeyModifiers(mod)),
+		e.waitActionable("visible", "enabled"),
+		e.on(func(node *cdp.Node) chromedp.Action {
+			return chromedp.KeyEventNode(node, text, chromedp.KeyModifiers(mod))
+		}),
 	)
 }
 
+// dispatchInputScript is a JavaScript snippet to notify a change of the value to listeners, including frameworks such as React or Vue.
+const dispatchInputScript = `
+	this.dispatchEvent(new Event('input', {bubbles: true}));
+	this.dispatchEvent(new Event('change', {bubbles: true}));
+`
+
 func (e Element) SetValue(L *lua.LState) {
 	value := L.CheckString(2)
-	e.tab.Run(L, fmt.Sprintf("%s:setValue(%q)", e.name, value), true, 0, chromedp.SetValue(e.ids(), value, chromedp.ByNodeID))
+	e.tab.Run(
+		L,
+		fmt.Sprintf("%s:setValue(%q)", e.name, value),
+		true,
+		0,
+		e.waitActionable("visible", "enabled"),
+		// The setter of the prototype is used because some frameworks override the setter of the element to track changes.
+		e.on(func(node *cdp.Node) chromedp.Action {
+			return callOnNode(node, `function(value) {
+			const desc = Object.getOwnPropertyDescriptor(Object.getPrototypeOf(this), 'value');
+			if (desc && desc.set) {
+				desc.set.call(this, value);
+			} else {
+				this.value = value;
+			}
+		`+dispatchInputScript+`}`, nil, value)
+		}),
+	)
+}
+
+func (e Element) SelectOption(L *lua.LState) {
+	var values []string
+	var name string
+
+	switch v := L.Get(2).(type) {
+	case lua.LString:
+		values = []string{string(v)}
+		name = fmt.Sprintf("%s:select(%q)", e.name, v)
+	case *lua.LTable:
+		quoted := make([]string, v.Len())
+		for i := 1; i <= v.Len(); i++ {
+			s, ok := v.RawGetInt(i).(lua.LString)
+			if !ok {
+				L.ArgError(2, "a string or a list of strings expected.")
+			}
+			values = append(values, string(s))
+			quoted[i-1] = fmt.Sprintf("%q", s)
+		}
+		name = fmt.Sprintf("%s:select({%s})", e.name, strings.Join(quoted, ", "))
+	default:
+		L.ArgError(2, "a string or a list of strings expected.")
+	}
+
+	var msg string
+	e.tab.Run(
+		L,
+		name,
+		true,
+		0,
+		e.waitActionable("visible", "enabled"),
+		e.on(func(node *cdp.Node) chromedp.Action {
+			return callOnNode(node, `function(values) {
+			if (this.tagName !== 'SELECT') {
+				return 'element is not a select';
+			}
+			const options = Array.from(this.options);
+			const found = [];
+			for (const v of values) {
+				const o = options.find(o => o.value === v) || options.find(o => o.label === v || o.text.trim() === v);
+				if (!o) {
+					return 'no such option: ' + JSON.stringify(v);
+				}
+				found.push(o);
+			}
+			if (!this.multiple && found.length > 1) {
+				return 'element is not a multiple select';
+			}
+			for (const o of options) {
+				o.selected = found.includes(o);
+			}
+		`+dispatchInputScript+`
+			return '';
+		}`, &msg, values)
+		}),
+		chromedp.ActionFunc(func(ctx context.Context) error {
+			if msg != "" {
+				return errors.New(msg)
+			}
+			return nil
+		}),
+	)
+}
+
+// setChecked clicks the element if the checked state is not the same as `checked`.
+func (e Element) setChecked(L *lua.LState, taskName string, checked bool) {
+	getState := func(ctx context.Context, node *cdp.Node) (bool, error) {
+		var state *bool
+		err := callOnNode(node, `function() {
+			if (this.tagName === 'INPUT' && (this.type === 'checkbox' || this.type === 'radio')) {
+				return this.checked;
+			}
+			if (this.hasAttribute('aria-checked')) {
+				return this.getAttribute('aria-checked') === 'true';
+			}
+			return null;
+		}`, &state).Do(ctx)
+		if err != nil {
+			return false, err
+		}
+		if state == nil {
+			return false, errors.New("element is not a checkbox or radio button")
+		}
+		return *state, nil
+	}
+
+	e.tab.Run(L, taskName, true, 0, e.waitActionable("visible", "stable", "enabled"), chromedp.ActionFunc(func(ctx context.Context) error {
+		node, err := e.node(ctx)
+		if err != nil {
+			return err
+		}
+
+		state, err := getState(ctx, node)
+		if err != nil || state == checked {
+			return err
+		}
+
+		if err := chromedp.MouseClickNode(node).Do(ctx); err != nil {
+			return err
+		}
+
+		if state, err = getState(ctx, node); err != nil {
+			return err
+		} else if state != checked {
+			return errors.New("the checked state did not change by click")
+		}
+		return nil
+	}))
+}
+
+func (e Element) Check(L *lua.LState) {
+	e.setChecked(L, fmt.Sprintf("%s:check()", e.name), true)
 }
 
+func (e Element) Uncheck(L *lua.LState) {
+	e.setChecked(L, fmt.Sprintf("%s:uncheck()", e.name), false)
+}
+
+// defaultNavigationTimeout is the default timeout of element:click{waitNavigation=true}.
+const defaultNavigationTimeout = 30 * time.Second
+
 func (e Element) Click(L *lua.LState) {
-	button := L.OptString(2, "left")
+	button := "left"
+	waitNavigation := false
+	timeout := defaultNavigationTimeout
+
+	switch v := L.Get(2).(type) {
+	case *lua.LNilType:
+	case lua.LString:
+		button = string(v)
+	case *lua.LTable:
+		if b, ok := L.GetField(v, "button").(lua.LString); ok {
+			button = string(b)
+		}
+		waitNavigation = lua.LVAsBool(L.GetField(v, "waitNavigation"))
+		if x, ok := L.GetField(v, "timeout").(lua.LNumber); ok {
+			timeout = time.Duration(float64(x) * float64(time.Millisecond))
+		}
+	default:
+		L.ArgError(2, "a nil, a string, or a table expected.")
+	}
 
 	var name string
-	if button == "left" {
+	switch {
+	case waitNavigation:
+		name = fmt.Sprintf("%s:click{button=%q, waitNavigation=true}", e.name, button)
+	case button == "left":
 		name = fmt.Sprintf("%s:click()", e.name)
-	} else {
+	default:
 		name = fmt.Sprintf("%s:click(%q)", e.name, button)
 	}
 
-	e.tab.Run(L, name, true, 0, chromedp.MouseClickNode(e.node, chromedp.Button(button)))
+	var action chromedp.Action = e.on(func(node *cdp.Node) chromedp.Action {
+		return chromedp.MouseClickNode(node, chromedp.Button(button))
+	})
+	if waitNavigation {
+		before := e.tab.loading.Navigations()
+		action = chromedp.Tasks{
+			action,
+			chromedp.ActionFunc(func(ctx context.Context) error {
+				wctx, cancel := context.WithTimeout(ctx, timeout)
+				defer cancel()
+
+				err := e.tab.loading.WaitNavigation(wctx, before)
+				if err != nil && ctx.Err() == nil {
+					return fmt.Errorf("navigation did not happen within %s after click", timeout)
+				}
+				return err
+			}),
+		}
+	}
+
+	e.tab.Run(L, name, true, 0, e.waitActionable("visible", "stable", "enabled"), action)
+}
+
+func (e Element) Hover(L *lua.LState) {
+	e.tab.Run(L, fmt.Sprintf("%s:hover()", e.name), true, 0, e.waitActionable("visible", "stable"), e.on(func(node *cdp.Node) chromedp.Action {
+		return e.tab.mouse.MoveToNode(node, 1)
+	}))
+}
+
+func (e Element) DragTo(L *lua.LState) {
+	var name string
+	var moveToTarget chromedp.Action
+	var waitTarget chromedp.Action = chromedp.Tasks{}
+
+	switch v := L.Get(2).(type) {
+	case *lua.LUserData:
+		target, ok := v.Value.(Element)
+		if !ok {
+			L.ArgError(2, "an element or a table with x and y expected.")
+		}
+		name = fmt.Sprintf("%s:dragTo(%s)", e.name, target.name)
+		moveToTarget = target.on(func(node *cdp.Node) chromedp.Action {
+			return e.tab.mouse.MoveToNode(node, 10)
+		})
+		waitTarget = target.waitActionable("visible")
+	case *lua.LTable:
+		x, y := CheckPoint(L, 2, v)
+		name = fmt.Sprintf("%s:dragTo(%s)", e.name, formatPoint(x, y))
+		moveToTarget = e.tab.mouse.Move(x, y, 10)
+	default:
+		L.ArgError(2, "an element or a table with x and y expected.")
+	}
+
+	e.tab.Run(
+		L,
+		name,
+		true,
+		0,
+		e.waitActionable("visible", "stable"),
+		waitTarget,
+		e.on(func(node *cdp.Node) chromedp.Action {
+			return e.tab.mouse.MoveToNode(node, 1)
+		}),
+		e.tab.mouse.Press(true),
+		moveToTarget,
+		e.tab.mouse.Press(false),
+	)
+}
+
+func (e Element) ScrollIntoView(L *lua.LState) {
+	e.tab.Run(L, fmt.Sprintf("%s:scrollIntoView()", e.name), true, 0, e.on(func(node *cdp.Node) chromedp.Action {
+		return dom.ScrollIntoViewIfNeeded().WithNodeID(node.NodeID)
+	}))
+}
+
+func (e Element) Upload(L *lua.LState) {
+	// The paths must not be nil, because CDP rejects null to clear files.
+	paths := []string{}
+	var quoted []string
+	for i := 2; i <= L.GetTop(); i++ {
+		name := L.CheckString(i)
+		p, ok := e.tab.env.storage.Lookup(name)
+		if !ok {
+			L.ArgError(i, fmt.Sprintf("no such file: %q", name))
+		}
+		paths = append(paths, p)
+		quoted = append(quoted, fmt.Sprintf("%q", name))
+	}
+
+	e.tab.Run(
+		L,
+		fmt.Sprintf("%s:upload(%s)", e.name, strings.Join(quoted, ", ")),
+		true,
+		0,
+		e.on(func(node *cdp.Node) chromedp.Action {
+			return dom.SetFileInputFiles(paths).WithNodeID(node.NodeID)
+		}),
+	)
 }
 
 func (e Element) Submit(L *lua.LState) {
-	e.tab.Run(L, fmt.Sprintf("%s:submit()", e.name), true, 0, chromedp.Submit(e.ids(), chromedp.ByNodeID))
+	e.tab.Run(L, fmt.Sprintf("%s:submit()", e.name), true, 0, e.byNodeID(func(ids []cdp.NodeID) chromedp.Action {
+		return chromedp.Submit(ids, chromedp.ByNodeID)
+	}))
 }
 
 func (e Element) Focus(L *lua.LState) {
-	e.tab.Run(L, fmt.Sprintf("%s:focus()", e.name), false, 0, chromedp.Focus(e.ids(), chromedp.ByNodeID))
+	e.tab.Run(L, fmt.Sprintf("%s:focus()", e.name), false, 0, e.byNodeID(func(ids []cdp.NodeID) chromedp.Action {
+		return chromedp.Focus(ids, chromedp.ByNodeID)
+	}))
 }
 
 func (e Element) Blur(L *lua.LState) {
-	e.tab.Run(L, fmt.Sprintf("%s:blur()", e.name), false, 0, chromedp.Blur(e.ids(), chromedp.ByNodeID))
+	e.tab.Run(L, fmt.Sprintf("%s:blur()", e.name), false, 0, e.byNodeID(func(ids []cdp.NodeID) chromedp.Action {
+		return chromedp.Blur(ids, chromedp.ByNodeID)
+	}))
 }
 
 func (e Element) Screenshot(L *lua.LState) {
@@ -216,7 +870,10 @@ func (e Element) Screenshot(L *lua.LState) {
 		fmt.Sprintf("%s:screenshot(%v)", e.name, name),
 		false,
 		0,
-		chromedp.Screenshot(e.ids(), &buf, chromedp.ByNodeID),
+		e.waitActionable("visible", "stable"),
+		e.byNodeID(func(ids []cdp.NodeID) chromedp.Action {
+			return chromedp.Screenshot(ids, &buf, chromedp.ByNodeID)
+		}),
 		chromedp.ActionFunc(func(ctx context.Context) error {
 			return e.tab.Save(name, ".png", buf)
 		}),
@@ -225,38 +882,181 @@ func (e Element) Screenshot(L *lua.LState) {
 
 func (e Element) GetText(L *lua.LState) int {
 	var text string
-	e.tab.Run(L, fmt.Sprintf("%s.text", e.name), false, 0, chromedp.Text(e.ids(), &text, chromedp.ByNodeID))
+	e.tab.Run(L, fmt.Sprintf("%s.text", e.name), false, 0, e.byNodeID(func(ids []cdp.NodeID) chromedp.Action {
+		return chromedp.Text(ids, &text, chromedp.ByNodeID)
+	}))
 	L.Push(lua.LString(text))
 	return 1
 }
 
 func (e Element) GetInnerHTML(L *lua.LState) int {
 	var html string
-	e.tab.Run(L, fmt.Sprintf("%s.innerHTML", e.name), false, 0, chromedp.InnerHTML(e.ids(), &html, chromedp.ByNodeID))
+	e.tab.Run(L, fmt.Sprintf("%s.innerHTML", e.name), false, 0, e.byNodeID(func(ids []cdp.NodeID) chromedp.Action {
+		return chromedp.InnerHTML(ids, &html, chromedp.ByNodeID)
+	}))
 	L.Push(lua.LString(html))
 	return 1
 }
 
 func (e Element) GetOuterHTML(L *lua.LState) int {
 	var html string
-	e.tab.Run(L, fmt.Sprintf("%s.outerHTML", e.name), false, 0, chromedp.OuterHTML(e.ids(), &html, chromedp.ByNodeID))
+	e.tab.Run(L, fmt.Sprintf("%s.outerHTML", e.name), false, 0, e.byNodeID(func(ids []cdp.NodeID) chromedp.Action {
+		return chromedp.OuterHTML(ids, &html, chromedp.ByNodeID)
+	}))
 	L.Push(lua.LString(html))
 	return 1
 }
 
 func (e Element) GetValue(L *lua.LState) int {
 	var value string
-	e.tab.Run(L, fmt.Sprintf("%s.value", e.name), false, 0, chromedp.Value(e.ids(), &value, chromedp.ByNodeID))
+	e.tab.Run(L, fmt.Sprintf("%s.value", e.name), false, 0, e.byNodeID(func(ids []cdp.NodeID) chromedp.Action {
+		return chromedp.Value(ids, &value, chromedp.ByNodeID)
+	}))
+	L.Push(lua.LString(value))
+	return 1
+}
+
+func (e Element) GetRect(L *lua.LState) int {
+	var rect *struct {
+		X      float64 `json:"x"`
+		Y      float64 `json:"y"`
+		Width  float64 `json:"width"`
+		Height float64 `json:"height"`
+	}
+	e.tab.Run(L, fmt.Sprintf("%s.rect", e.name), false, 0, e.on(func(node *cdp.Node) chromedp.Action {
+		return callOnNode(node, `function() {
+			// The element is not rendered, such as display:none.
+			if (this.getClientRects().length === 0) return null;
+
+			const r = this.getBoundingClientRect();
+			return {x: r.x, y: r.y, width: r.width, height: r.height};
+		}`, &rect)
+	}))
+
+	if rect == nil {
+		return 0
+	}
+
+	tbl := L.NewTable()
+	L.SetField(tbl, "x", lua.LNumber(rect.X))
+	L.SetField(tbl, "y", lua.LNumber(rect.Y))
+	L.SetField(tbl, "width", lua.LNumber(rect.Width))
+	L.SetField(tbl, "height", lua.LNumber(rect.Height))
+	L.Push(tbl)
+	return 1
+}
+
+func (e Element) getBool(L *lua.LState, property, function string) int {
+	var b bool
+	e.tab.Run(L, fmt.Sprintf("%s.%s", e.name, property), false, 0, e.on(func(node *cdp.Node) chromedp.Action {
+		return callOnNode(node, function, &b)
+	}))
+	L.Push(lua.LBool(b))
+	return 1
+}
+
+func (e Element) GetVisible(L *lua.LState) int {
+	return e.getBool(L, "visible", `function() {
+		const r = this.getBoundingClientRect();
+		const style = getComputedStyle(this);
+		return r.width > 0 && r.height > 0 && style.visibility !== 'hidden' && style.display !== 'none';
+	}`)
+}
+
+func (e Element) GetEnabled(L *lua.LState) int {
+	return e.getBool(L, "enabled", `function() { return !this.matches(':disabled') }`)
+}
+
+func (e Element) GetChecked(L *lua.LState) int {
+	return e.getBool(L, "checked", `function() { return this.matches(':checked') }`)
+}
+
+func (e Element) GetTagName(L *lua.LState) int {
+	L.Push(lua.LString(e.ref.Current().NodeName))
+	return 1
+}
+
+func (e Element) Style(L *lua.LState) int {
+	prop := L.CheckString(2)
+
+	var value string
+	e.tab.Run(
+		L,
+		fmt.Sprintf("%s:style(%q)", e.name, prop),
+		false,
+		0,
+		e.on(func(node *cdp.Node) chromedp.Action {
+			return callOnNode(node, `function(prop) { return getComputedStyle(this).getPropertyValue(prop) }`, &value, prop)
+		}),
+	)
 	L.Push(lua.LString(value))
 	return 1
 }
 
+// shadowRootOf returns the shadow root of the node, or nil if the node doesn't have it.
+func shadowRootOf(ctx context.Context, node *cdp.Node) (*cdp.Node, error) {
+	// DOM.describeNode is used instead of JavaScript, to access closed shadow roots as well.
+	n, err := dom.DescribeNode().WithNodeID(node.NodeID).Do(ctx)
+	if err != nil || len(n.ShadowRoots) == 0 {
+		return nil, err
+	}
+
+	ids, err := dom.PushNodesByBackendIDsToFrontend([]cdp.BackendNodeID{n.ShadowRoots[0].BackendNodeID}).Do(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var nodes []*cdp.Node
+	if err := chromedp.Nodes(ids, &nodes, chromedp.ByNodeID).Do(ctx); err != nil {
+		return nil, err
+	}
+	return nodes[0], nil
+}
+
+func (e Element) GetShadowRoot(L *lua.LState) int {
+	name := fmt.Sprintf("%s.shadowRoot", e.name)
+
+	locate := func(ctx context.Context) (*cdp.Node, error) {
+		host, err := e.node(ctx)
+		if err != nil {
+			return nil, err
+		}
+		return shadowRootOf(ctx, host)
+	}
+
+	var root *cdp.Node
+	e.tab.Run(L, name, false, 0, chromedp.ActionFunc(func(ctx context.Context) (err error) {
+		root, err = locate(ctx)
+		return err
+	}))
+
+	if root == nil {
+		return 0
+	}
+	L.Push(newElement(e.tab, name, root, locate).ToLua(L))
+	return 1
+}
+
+// GetAttached reports if the node of the element is still in the document.
+// It doesn't find the element again unlike other methods, so that scripts can check if the page has been re-rendered.
+func (e Element) GetAttached(L *lua.LState) int {
+	var ok bool
+	e.tab.Run(L, fmt.Sprintf("%s.attached", e.name), false, 0, chromedp.ActionFunc(func(ctx context.Context) (err error) {
+		ok, err = isAttached(ctx, e.ref.Current())
+		return err
+	}))
+	L.Push(lua.LBool(ok))
+	return 1
+}
+
 func (e Element) GetAttribute(L *lua.LState) int {
 	name := L.CheckString(2)
 
 	var value string
 	var ok bool
-	e.tab.Run(L, fmt.Sprintf("%s[%q]", e.name, name), false, 0, chromedp.AttributeValue(e.ids(), name, &value, &ok, chromedp.ByNodeID))
+	e.tab.Run(L, fmt.Sprintf("%s[%q]", e.name, name), false, 0, e.byNodeID(func(ids []cdp.NodeID) chromedp.Action {
+		return chromedp.AttributeValue(ids, name, &value, &ok, chromedp.ByNodeID)
+	}))
 
 	if ok {
 		L.Push(lua.LString(value))
@@ -282,20 +1082,33 @@ func RegisterElementType(ctx context.Context, L *lua.LState) {
 			L.Push(e.SelectAll(L, query))
 			return 1
 		}),
-		"sendKeys":   fn(Element.SendKeys),
-		"setValue":   fn(Element.SetValue),
-		"click":      fn(Element.Click),
-		"submit":     fn(Element.Submit),
-		"focus":      fn(Element.Focus),
-		"blur":       fn(Element.Blur),
-		"screenshot": fn(Element.Screenshot),
+		"sendKeys":       fn(Element.SendKeys),
+		"setValue":       fn(Element.SetValue),
+		"click":          fn(Element.Click),
+		"hover":          fn(Element.Hover),
+		"dragTo":         fn(Element.DragTo),
+		"scrollIntoView": fn(Element.ScrollIntoView),
+		"submit":         fn(Element.Submit),
+		"focus":          fn(Element.Focus),
+		"blur":           fn(Element.Blur),
+		"screenshot":     fn(Element.Screenshot),
+		"style": L.NewFunction(func(L *lua.LState) int {
+			return CheckElement(L).Style(L)
+		}),
 	}
 
 	getters := map[string]func(Element, *lua.LState) int{
-		"text":      Element.GetText,
-		"innerHTML": Element.GetInnerHTML,
-		"outerHTML": Element.GetOuterHTML,
-		"value":     Element.GetValue,
+		"text":       Element.GetText,
+		"innerHTML":  Element.GetInnerHTML,
+		"outerHTML":  Element.GetOuterHTML,
+		"value":      Element.GetValue,
+		"rect":       Element.GetRect,
+		"visible":    Element.GetVisible,
+		"enabled":    Element.GetEnabled,
+		"checked":    Element.GetChecked,
+		"tagName":    Element.GetTagName,
+		"shadowRoot": Element.GetShadowRoot,
+		"attached":   Element.GetAttached,
 	}
 
 	query := L.SetFuncs(L.NewTypeMetatable("element"), map[string]lua.LGFunction{
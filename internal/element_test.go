@@ -0,0 +1,32 @@
+package webscenario
+
+import (
+	"testing"
+)
+
+func TestMatchFingerprint(t *testing.T) {
+	tests := []struct {
+		orig    []string
+		current []string
+		index   int
+		want    int
+		amb     bool
+	}{
+		{[]string{"a", "b", "c"}, []string{"a", "b", "c"}, 1, 1, false},
+		{[]string{"a", "b", "c"}, []string{"b", "c"}, 1, 0, false},
+		{[]string{"a", "b", "c"}, []string{"c", "b", "a"}, 0, 2, false},
+		{[]string{"a", "b", "c"}, []string{"a", "x", "c"}, 1, -1, false},
+		{[]string{"a", "b", "c"}, []string{"a", "c"}, 1, -1, false},
+		{[]string{"a", "a", "b"}, []string{"a", "a", "b"}, 1, 1, false},
+		{[]string{"a", "a", "b"}, []string{"b", "a", "a"}, 1, -1, true},
+		{[]string{"a", "a", "b"}, []string{"a", "b"}, 0, 0, false},
+		{[]string{"a"}, []string{}, 0, -1, false},
+	}
+
+	for _, tt := range tests {
+		actual, amb := matchFingerprint(tt.orig, tt.current, tt.index)
+		if actual != tt.want || amb != tt.amb {
+			t.Errorf("%v[%d] in %v: expected %d, %v but got %d, %v", tt.orig, tt.index, tt.current, tt.want, tt.amb, actual, amb)
+		}
+	}
+}
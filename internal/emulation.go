@@ -0,0 +1,194 @@
+package webscenario
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/chromedp/cdproto/browser"
+	"github.com/chromedp/cdproto/emulation"
+	"github.com/chromedp/cdproto/network"
+	"github.com/chromedp/chromedp"
+	"github.com/chromedp/chromedp/device"
+	"github.com/yuin/gopher-lua"
+)
+
+// NetworkPresets is network conditions that the same as Chrome DevTools' ones.
+var NetworkPresets = map[string]*network.EmulateNetworkConditionsParams{
+	"offline": network.EmulateNetworkConditions(true, 0, -1, -1),
+	"3g":      network.EmulateNetworkConditions(false, 2000, 500*1000/8*0.8, 500*1000/8*0.8).WithConnectionType(network.ConnectionTypeCellular3g),
+	"slow-4g": network.EmulateNetworkConditions(false, 562.5, 1.6*1000*1000/8*0.9, 750*1000/8*0.9).WithConnectionType(network.ConnectionTypeCellular4g),
+	"fast-4g": network.EmulateNetworkConditions(false, 165, 9*1000*1000/8*0.9, 1.5*1000*1000/8*0.9).WithConnectionType(network.ConnectionTypeCellular4g),
+}
+
+func networkPresetNames() string {
+	names := make([]string, 0, len(NetworkPresets))
+	for k := range NetworkPresets {
+		names = append(names, fmt.Sprintf("%q", k))
+	}
+	sort.Strings(names)
+	return strings.Join(names, ", ")
+}
+
+// CheckNetworkConditions reads network conditions from a preset name or a table.
+// It returns nil if the value is nil, that means disable emulation.
+// The n is used to report argument error.
+func CheckNetworkConditions(L *lua.LState, n int, v lua.LValue) *network.EmulateNetworkConditionsParams {
+	switch v := v.(type) {
+	case *lua.LNilType:
+		return nil
+	case lua.LString:
+		if p, ok := NetworkPresets[strings.ToLower(string(v))]; ok {
+			return p
+		}
+		L.ArgError(n, fmt.Sprintf("unknown network preset %q. it should be one of %s.", v, networkPresetNames()))
+	case *lua.LTable:
+		p := network.EmulateNetworkConditions(lua.LVAsBool(L.GetField(v, "offline")), 0, -1, -1)
+		for name, x := range map[string]*float64{
+			"latency":  &p.Latency,
+			"download": &p.DownloadThroughput,
+			"upload":   &p.UploadThroughput,
+		} {
+			switch f := L.GetField(v, name).(type) {
+			case *lua.LNilType:
+			case lua.LNumber:
+				*x = float64(f)
+			default:
+				L.ArgError(n, fmt.Sprintf("%s field is expected be a number.", name))
+			}
+		}
+		return p
+	default:
+		L.ArgError(n, "a nil, a string, or a table expected.")
+	}
+	return nil
+}
+
+// EmulateFunc makes an action to emulate something from a Lua value.
+// The n is used to report argument error.
+type EmulateFunc func(L *lua.LState, n int, v lua.LValue) chromedp.Action
+
+// Emulations is a map of the option name for tab.new and EmulateFunc.
+var Emulations = map[string]EmulateFunc{
+	"cpu":         EmulateCPU,
+	"timezone":    EmulateTimezone,
+	"locale":      EmulateLocale,
+	"geolocation": EmulateGeolocation,
+	"media":       EmulateMedia,
+}
+
+func EmulateCPU(L *lua.LState, n int, v lua.LValue) chromedp.Action {
+	switch v := v.(type) {
+	case *lua.LNilType:
+		return emulation.SetCPUThrottlingRate(1)
+	case lua.LNumber:
+		if v >= 1 {
+			return emulation.SetCPUThrottlingRate(float64(v))
+		}
+	}
+	L.ArgError(n, "cpu throttling rate is expected be a number greater than or equal to 1.")
+	return nil
+}
+
+func EmulateTimezone(L *lua.LState, n int, v lua.LValue) chromedp.Action {
+	switch v := v.(type) {
+	case *lua.LNilType:
+		return emulation.SetTimezoneOverride("")
+	case lua.LString:
+		return emulation.SetTimezoneOverride(string(v))
+	}
+	L.ArgError(n, "timezone is expected be a string.")
+	return nil
+}
+
+func EmulateLocale(L *lua.LState, n int, v lua.LValue) chromedp.Action {
+	switch v := v.(type) {
+	case *lua.LNilType:
+		return emulation.SetLocaleOverride()
+	case lua.LString:
+		return emulation.SetLocaleOverride().WithLocale(string(v))
+	}
+	L.ArgError(n, "locale is expected be a string.")
+	return nil
+}
+
+func EmulateGeolocation(L *lua.LState, n int, v lua.LValue) chromedp.Action {
+	switch v := v.(type) {
+	case *lua.LNilType:
+		return emulation.ClearGeolocationOverride()
+	case *lua.LTable:
+		lat, ok1 := L.GetField(v, "latitude").(lua.LNumber)
+		lng, ok2 := L.GetField(v, "longitude").(lua.LNumber)
+		if !ok1 || !ok2 {
+			L.ArgError(n, "geolocation is expected be a table that has latitude and longitude.")
+		}
+		accuracy := lua.LNumber(1)
+		if a, ok := L.GetField(v, "accuracy").(lua.LNumber); ok {
+			accuracy = a
+		}
+
+		return chromedp.Tasks{
+			browser.GrantPermissions([]browser.PermissionType{browser.PermissionTypeGeolocation}),
+			emulation.SetGeolocationOverride().
+				WithLatitude(float64(lat)).
+				WithLongitude(float64(lng)).
+				WithAccuracy(float64(accuracy)),
+		}
+	}
+	L.ArgError(n, "geolocation is expected be a table.")
+	return nil
+}
+
+var mediaFeatureNames = map[string]string{
+	"colorScheme":   "prefers-color-scheme",
+	"reducedMotion": "prefers-reduced-motion",
+}
+
+func EmulateMedia(L *lua.LState, n int, v lua.LValue) chromedp.Action {
+	switch v := v.(type) {
+	case *lua.LNilType:
+		return emulation.SetEmulatedMedia()
+	case *lua.LTable:
+		var features []*emulation.MediaFeature
+		v.ForEach(func(k, x lua.LValue) {
+			name := k.String()
+			if s, ok := mediaFeatureNames[name]; ok {
+				name = s
+			}
+			features = append(features, &emulation.MediaFeature{Name: name, Value: x.String()})
+		})
+		sort.Slice(features, func(i, j int) bool {
+			return features[i].Name < features[j].Name
+		})
+		return emulation.SetEmulatedMedia().WithFeatures(features)
+	}
+	L.ArgError(n, "media is expected be a table.")
+	return nil
+}
+
+var devices []device.Info
+
+func init() {
+	for d := device.Reset + 1; d <= device.MotoG4landscape; d++ {
+		devices = append(devices, d.Device())
+	}
+}
+
+// DeviceNames returns names of device presets.
+func DeviceNames() []string {
+	names := make([]string, len(devices))
+	for i, d := range devices {
+		names[i] = d.Name
+	}
+	return names
+}
+
+// LookupDevice finds a device preset by name in case-insensitive.
+func LookupDevice(name string) (device.Info, bool) {
+	for _, d := range devices {
+		if strings.EqualFold(d.Name, name) {
+			return d, true
+		}
+	}
+	return device.Info{}, false
+}
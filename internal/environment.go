@@ -5,6 +5,7 @@ import (
 	"errors"
 	"fmt"
 	"sync"
+	"time"
 
 	"github.com/yuin/gopher-lua"
 )
@@ -25,6 +26,9 @@ type Environment struct {
 	storage *Storage
 	saveWG  sync.WaitGroup
 	errch   chan error
+	closed  bool
+
+	stepTimeout time.Duration
 
 	EnableRecording bool
 }
@@ -41,11 +45,17 @@ func NewEnvironment(ctx context.Context, logger *Logger, s *Storage, arg Arg) *E
 		logger:  logger,
 		storage: s,
 		errch:   make(chan error, 1),
+
+		stepTimeout: arg.StepTimeout,
+	}
+	if env.stepTimeout <= 0 {
+		env.stepTimeout = DefaultStepTimeout
 	}
 	env.Lock()
 
 	RegisterLogger(L, logger)
 	RegisterElementType(ctx, L)
+	RegisterFrameType(ctx, L)
 	RegisterTabType(ctx, env)
 	RegisterTime(ctx, env)
 	RegisterAssert(L)
@@ -67,6 +77,7 @@ func (env *Environment) Close() error {
 	env.lua.Close()
 	env.stop()
 	env.saveWG.Wait()
+	env.closed = true
 	close(env.errch)
 	return nil
 }
@@ -132,7 +143,9 @@ func (env *Environment) CallEventHandler(f *lua.LFunction, arg *lua.LTable, nret
 
 	L.Push(f)
 	L.Push(arg)
-	env.errch <- L.PCall(1, nret, nil)
+	if err := L.PCall(1, nret, nil); err != nil {
+		env.abort(err)
+	}
 
 	var result []lua.LValue
 	for i := 1; i <= nret; i++ {
@@ -141,6 +154,25 @@ func (env *Environment) CallEventHandler(f *lua.LFunction, arg *lua.LTable, nret
 	return result
 }
 
+// Abort stops the running script with an error.
+func (env *Environment) Abort(err error) {
+	env.Lock()
+	defer env.Unlock()
+	env.abort(err)
+}
+
+func (env *Environment) abort(err error) {
+	if env.closed {
+		return
+	}
+	select {
+	case env.errch <- err:
+	default:
+		// The script is already stopping by another error.
+		env.logger.Print(lua.LString(err.Error()))
+	}
+}
+
 func (env *Environment) StartTask(where, taskName string) {
 	env.logger.StartTask(where, taskName)
 }
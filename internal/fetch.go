@@ -3,15 +3,23 @@ package webscenario
 import (
 	"bytes"
 	"context"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"io"
 	"net/http"
 	"net/http/cookiejar"
 	"net/url"
+	"os"
+	"sort"
 	"strings"
+	"sync"
 	"time"
 
+	"github.com/chromedp/cdproto/cdp"
+	"github.com/chromedp/cdproto/network"
+	"github.com/chromedp/cdproto/storage"
+	"github.com/chromedp/chromedp"
 	"github.com/yuin/gopher-lua"
 )
 
@@ -75,20 +83,141 @@ func PackFetchResponse(env *Environment, L *lua.LState, resp *http.Response, bod
 }
 
 type CookieJar struct {
-	id   int
-	jar  *cookiejar.Jar
-	urls map[string]*url.URL
+	sync.Mutex
+
+	id      int
+	jar     *cookiejar.Jar
+	urls    map[string]*url.URL
+	records map[string]savedCookie
 }
 
 func NewCookieJar(id int) (*CookieJar, error) {
 	jar, err := cookiejar.New(nil)
 	return &CookieJar{
-		id:   id,
-		jar:  jar,
-		urls: make(map[string]*url.URL),
+		id:      id,
+		jar:     jar,
+		urls:    make(map[string]*url.URL),
+		records: make(map[string]savedCookie),
 	}, err
 }
 
+// savedCookie is a cookie with the URL that set it, to keep attributes that cookiejar.Jar doesn't return, and to restore cookies into a CookieJar.
+type savedCookie struct {
+	URL      string     `json:"url"`
+	Name     string     `json:"name"`
+	Value    string     `json:"value"`
+	Path     string     `json:"path,omitempty"`
+	Domain   string     `json:"domain,omitempty"`
+	Expires  *time.Time `json:"expires,omitempty"`
+	Secure   bool       `json:"secure,omitempty"`
+	HttpOnly bool       `json:"httponly,omitempty"`
+	SameSite string     `json:"samesite,omitempty"`
+}
+
+func (c savedCookie) Cookie() *http.Cookie {
+	h := &http.Cookie{
+		Name:     c.Name,
+		Value:    c.Value,
+		Path:     c.Path,
+		Domain:   c.Domain,
+		Secure:   c.Secure,
+		HttpOnly: c.HttpOnly,
+		SameSite: parseSameSite(c.SameSite),
+	}
+	if c.Expires != nil {
+		h.Expires = *c.Expires
+	}
+	return h
+}
+
+func (c savedCookie) Expired(now time.Time) bool {
+	return c.Expires != nil && !c.Expires.After(now)
+}
+
+// defaultCookiePath returns the default path of cookies that set by the URL, as RFC 6265 section 5.1.4.
+func defaultCookiePath(path string) string {
+	if i := strings.LastIndexByte(path, '/'); i > 0 {
+		return path[:i]
+	}
+	return "/"
+}
+
+func (j *CookieJar) record(u *url.URL, c *http.Cookie) {
+	now := time.Now()
+
+	path := c.Path
+	if path == "" || path[0] != '/' {
+		path = defaultCookiePath(u.Path)
+	}
+	key := strings.Join([]string{u.Scheme, u.Host, c.Domain, path, c.Name}, ";")
+
+	r := savedCookie{
+		URL:      u.String(),
+		Name:     c.Name,
+		Value:    c.Value,
+		Path:     c.Path,
+		Domain:   c.Domain,
+		Secure:   c.Secure,
+		HttpOnly: c.HttpOnly,
+		SameSite: formatSameSite(c.SameSite),
+	}
+	if c.MaxAge > 0 {
+		e := now.Add(time.Duration(c.MaxAge) * time.Second)
+		r.Expires = &e
+	} else if c.MaxAge < 0 {
+		r.Expires = &now
+	} else if !c.Expires.IsZero() {
+		e := c.Expires
+		r.Expires = &e
+	}
+
+	if r.Expired(now) {
+		delete(j.records, key)
+	} else {
+		j.records[key] = r
+	}
+}
+
+// MarshalJSON encodes all cookies that are not expired yet.
+func (j *CookieJar) MarshalJSON() ([]byte, error) {
+	j.Lock()
+	defer j.Unlock()
+
+	keys := make([]string, 0, len(j.records))
+	for k := range j.records {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	now := time.Now()
+	rs := make([]savedCookie, 0, len(keys))
+	for _, k := range keys {
+		if r := j.records[k]; !r.Expired(now) {
+			rs = append(rs, r)
+		}
+	}
+
+	return json.MarshalIndent(rs, "", "  ")
+}
+
+// UnmarshalJSON restores cookies that encoded by MarshalJSON.
+func (j *CookieJar) UnmarshalJSON(data []byte) error {
+	var rs []savedCookie
+	if err := json.Unmarshal(data, &rs); err != nil {
+		return err
+	}
+
+	for _, r := range rs {
+		u, err := url.Parse(r.URL)
+		if err != nil {
+			return err
+		}
+		j.SetCookies(u, []*http.Cookie{r.Cookie()})
+	}
+
+	return nil
+}
+
 func CheckCookieJar(L *lua.LState, n int) *CookieJar {
 	ud := L.ToUserData(n)
 	if ud == nil {
@@ -104,14 +233,91 @@ func CheckCookieJar(L *lua.LState, n int) *CookieJar {
 }
 
 func (j *CookieJar) SetCookies(u *url.URL, cookies []*http.Cookie) {
+	j.Lock()
+	defer j.Unlock()
+
 	j.urls[u.String()] = u
 	j.jar.SetCookies(u, cookies)
+	for _, c := range cookies {
+		j.record(u, c)
+	}
 }
 
 func (j *CookieJar) Cookies(u *url.URL) []*http.Cookie {
 	return j.jar.Cookies(u)
 }
 
+// CookieParams makes parameters for network.SetCookies to copy cookies into a browser.
+// The attributes of cookies are kept, because the browser derives different defaults from the URL, such as the path.
+func (j *CookieJar) CookieParams() []*network.CookieParam {
+	j.Lock()
+	defer j.Unlock()
+
+	keys := make([]string, 0, len(j.records))
+	for k := range j.records {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	now := time.Now()
+	var ps []*network.CookieParam
+	for _, k := range keys {
+		r := j.records[k]
+		if r.Expired(now) {
+			continue
+		}
+
+		p := &network.CookieParam{
+			Name:     r.Name,
+			Value:    r.Value,
+			URL:      r.URL,
+			Path:     r.Path,
+			Domain:   r.Domain,
+			Secure:   r.Secure,
+			HTTPOnly: r.HttpOnly,
+		}
+		if p.Path == "" || p.Path[0] != '/' {
+			if u, err := url.Parse(r.URL); err == nil {
+				p.Path = defaultCookiePath(u.Path)
+			}
+		}
+		switch parseSameSite(r.SameSite) {
+		case http.SameSiteLaxMode:
+			p.SameSite = network.CookieSameSiteLax
+		case http.SameSiteStrictMode:
+			p.SameSite = network.CookieSameSiteStrict
+		case http.SameSiteNoneMode:
+			p.SameSite = network.CookieSameSiteNone
+		}
+		if r.Expires != nil {
+			e := cdp.TimeSinceEpoch(*r.Expires)
+			p.Expires = &e
+		}
+		ps = append(ps, p)
+	}
+	return ps
+}
+
+// SetCDPCookies copies cookies from a browser.
+func (j *CookieJar) SetCDPCookies(cookies []*network.Cookie) {
+	for _, c := range cookies {
+		h := CookieFromCDP(c)
+
+		u := &url.URL{Scheme: "http", Host: strings.TrimPrefix(c.Domain, "."), Path: c.Path}
+		if c.Secure {
+			u.Scheme = "https"
+		}
+
+		if strings.HasPrefix(c.Domain, ".") {
+			h.Domain = u.Host
+		} else {
+			h.Domain = ""
+		}
+
+		j.SetCookies(u, []*http.Cookie{h})
+	}
+}
+
 func (j *CookieJar) CookiesAsLua(L *lua.LState, u *url.URL) (*lua.LTable, bool) {
 	cs := j.Cookies(u)
 	if len(cs) == 0 {
@@ -120,36 +326,43 @@ func (j *CookieJar) CookiesAsLua(L *lua.LState, u *url.URL) (*lua.LTable, bool)
 
 	tbl := L.NewTable()
 	for _, c := range cs {
-		l := L.NewTable()
-		L.SetField(l, "name", lua.LString(c.Name))
-		L.SetField(l, "value", lua.LString(c.Value))
-		L.SetField(l, "path", lua.LString(c.Path))
-		L.SetField(l, "domain", lua.LString(c.Domain))
-		if !c.Expires.IsZero() {
-			L.SetField(l, "expires", lua.LNumber(c.Expires.UnixMilli()))
-		}
-		L.SetField(l, "secure", lua.LBool(c.Secure))
-		L.SetField(l, "httponly", lua.LBool(c.HttpOnly))
+		tbl.Append(PackCookie(L, c))
+	}
+	return tbl, true
+}
 
-		var samesite string
-		switch c.SameSite {
-		case http.SameSiteDefaultMode:
-			samesite = "default"
-		case http.SameSiteLaxMode:
-			samesite = "lax"
-		case http.SameSiteStrictMode:
-			samesite = "strict"
-		case http.SameSiteNoneMode:
-			samesite = "none"
-		}
-		L.SetField(l, "samesite", lua.LString(samesite))
+func (j *CookieJar) Save(s *Storage, name string) error {
+	data, err := j.MarshalJSON()
+	if err != nil {
+		return err
+	}
+	return s.Save(name, ".json", data)
+}
 
-		tbl.Append(l)
+// LoadCookieJar loads a cookie jar that saved by CookieJar.Save.
+// The name is looked up by Storage.Lookup, with or without ".json" extension.
+func LoadCookieJar(s *Storage, id int, name string) (*CookieJar, error) {
+	p, ok := s.Lookup(name)
+	if !ok && !strings.HasSuffix(name, ".json") {
+		p, ok = s.Lookup(name + ".json")
 	}
-	return tbl, true
+	if !ok {
+		p = name
+	}
+
+	data, err := os.ReadFile(p)
+	if err != nil {
+		return nil, err
+	}
+
+	j, err := NewCookieJar(id)
+	if err != nil {
+		return nil, err
+	}
+	return j, j.UnmarshalJSON(data)
 }
 
-func (j *CookieJar) ToLua(L *lua.LState) lua.LValue {
+func (j *CookieJar) ToLua(env *Environment, L *lua.LState) lua.LValue {
 	v := L.NewUserData()
 	v.Value = j
 	v.Metatable = L.SetFuncs(L.NewTable(), map[string]lua.LGFunction{
@@ -162,8 +375,15 @@ func (j *CookieJar) ToLua(L *lua.LState) lua.LValue {
 		"all": func(L *lua.LState) int {
 			j := CheckCookieJar(L, 1)
 
-			tbl := L.NewTable()
+			j.Lock()
+			urls := make(map[string]*url.URL, len(j.urls))
 			for s, u := range j.urls {
+				urls[s] = u
+			}
+			j.Unlock()
+
+			tbl := L.NewTable()
+			for s, u := range urls {
 				if cs, ok := j.CookiesAsLua(L, u); ok {
 					L.SetField(tbl, s, cs)
 				}
@@ -172,6 +392,43 @@ func (j *CookieJar) ToLua(L *lua.LState) lua.LValue {
 
 			return 1
 		},
+		"export": func(L *lua.LState) int {
+			j := CheckCookieJar(L, 1)
+			t := checkTabArg(L, 2)
+
+			if ps := j.CookieParams(); len(ps) > 0 {
+				t.Run(L, fmt.Sprintf("cookiejar#%d:export(tab#%d)", j.id, t.id), false, 0, network.SetCookies(ps))
+			}
+
+			L.Push(L.Get(1))
+			return 1
+		},
+		"import": func(L *lua.LState) int {
+			j := CheckCookieJar(L, 1)
+			t := checkTabArg(L, 2)
+
+			var cookies []*network.Cookie
+			t.Run(L, fmt.Sprintf("cookiejar#%d:import(tab#%d)", j.id, t.id), false, 0, chromedp.ActionFunc(func(ctx context.Context) (err error) {
+				// Storage.getCookies is used because Network.getCookies returns only cookies for the current page.
+				cookies, err = storage.GetCookies().Do(ctx)
+				return err
+			}))
+			j.SetCDPCookies(cookies)
+
+			L.Push(L.Get(1))
+			return 1
+		},
+		"save": func(L *lua.LState) int {
+			j := CheckCookieJar(L, 1)
+			name := L.CheckString(2)
+
+			if err := j.Save(env.storage, name); err != nil {
+				L.RaiseError("failed to save cookiejar: %s", err)
+			}
+
+			L.Push(L.Get(1))
+			return 1
+		},
 		"get": func(L *lua.LState) int {
 			j := CheckCookieJar(L, 1)
 
@@ -192,7 +449,7 @@ func (j *CookieJar) ToLua(L *lua.LState) lua.LValue {
 func RegisterFetch(ctx context.Context, env *Environment) {
 	jarID := 1
 
-	env.RegisterFunction("fetch", func(L *lua.LState) int {
+	fetch := func(L *lua.LState) int {
 		url := L.CheckString(1)
 		opts := L.OptTable(2, L.NewTable())
 
@@ -288,8 +545,28 @@ func RegisterFetch(ctx context.Context, env *Environment) {
 		})
 
 		L.Push(PackFetchResponse(env, L, ret.Resp, bytes.NewReader(ret.Body)))
-		L.Push(cookiejar.ToLua(L))
+		L.Push(cookiejar.ToLua(env, L))
 
 		return 2
+	}
+
+	env.RegisterTable("fetch", map[string]lua.LValue{
+		"loadjar": env.NewFunction(func(L *lua.LState) int {
+			name := L.CheckString(1)
+
+			j, err := LoadCookieJar(env.storage, jarID, name)
+			if err != nil {
+				L.RaiseError("failed to load cookiejar: %s", err)
+			}
+			jarID++
+
+			L.Push(j.ToLua(env, L))
+			return 1
+		}),
+	}, map[string]lua.LValue{
+		"__call": env.NewFunction(func(L *lua.LState) int {
+			L.Remove(1)
+			return fetch(L)
+		}),
 	})
 }
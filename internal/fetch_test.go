@@ -2,8 +2,13 @@ package webscenario
 
 import (
 	"net/http"
+	"net/url"
+	"sort"
 	"testing"
+	"time"
 
+	"github.com/chromedp/cdproto/cdp"
+	"github.com/chromedp/cdproto/network"
 	"github.com/google/go-cmp/cmp"
 	"github.com/yuin/gopher-lua"
 )
@@ -65,3 +70,76 @@ func TestUnpackFetchHeader(t *testing.T) {
 		}
 	}
 }
+
+func TestCookieJar_MarshalJSON(t *testing.T) {
+	u, _ := url.Parse("https://example.com/path/to/page")
+
+	orig, _ := NewCookieJar(1)
+	orig.SetCookies(u, []*http.Cookie{
+		{Name: "session", Value: "hello"},
+		{Name: "persistent", Value: "world", Path: "/", MaxAge: 3600, HttpOnly: true},
+		{Name: "expired", Value: "foobar", Expires: time.Now().Add(-time.Hour)},
+		{Name: "removed", Value: "removed"},
+	})
+	orig.SetCookies(u, []*http.Cookie{
+		{Name: "removed", Value: "removed", MaxAge: -1},
+	})
+
+	data, err := orig.MarshalJSON()
+	if err != nil {
+		t.Fatalf("failed to marshal: %s", err)
+	}
+
+	restored, _ := NewCookieJar(2)
+	if err := restored.UnmarshalJSON(data); err != nil {
+		t.Fatalf("failed to unmarshal: %s", err)
+	}
+
+	want := []string{"persistent=world", "session=hello"}
+	for _, j := range []*CookieJar{orig, restored} {
+		var got []string
+		for _, c := range j.Cookies(u) {
+			got = append(got, c.String())
+		}
+		sort.Strings(got)
+
+		if diff := cmp.Diff(want, got); diff != "" {
+			t.Errorf("unexpected cookies in cookiejar#%d:\n%s", j.id, diff)
+		}
+	}
+
+	if len(restored.records) != 2 {
+		t.Errorf("expected 2 records but got %d", len(restored.records))
+	}
+}
+
+func TestCookieJar_CookieParams(t *testing.T) {
+	u, _ := url.Parse("https://example.com/api/login")
+	expires := time.Date(2100, 1, 2, 3, 4, 5, 0, time.UTC)
+
+	jar, _ := NewCookieJar(1)
+	jar.SetCookies(u, []*http.Cookie{
+		{Name: "session", Value: "secret", Path: "/", HttpOnly: true, Secure: true, SameSite: http.SameSiteStrictMode},
+		{Name: "local", Value: "hello"},
+		{Name: "shared", Value: "world", Domain: "example.com", Expires: expires},
+	})
+
+	cdpExpires := cdp.TimeSinceEpoch(expires)
+	want := []*network.CookieParam{
+		{Name: "local", Value: "hello", URL: u.String(), Path: "/api"},
+		{Name: "session", Value: "secret", URL: u.String(), Path: "/", Secure: true, HTTPOnly: true, SameSite: network.CookieSameSiteStrict},
+		{Name: "shared", Value: "world", URL: u.String(), Path: "/api", Domain: "example.com", Expires: &cdpExpires},
+	}
+
+	got := jar.CookieParams()
+	sort.Slice(got, func(i, j int) bool {
+		return got[i].Name < got[j].Name
+	})
+
+	timeEqual := cmp.Comparer(func(x, y cdp.TimeSinceEpoch) bool {
+		return x.Time().Equal(y.Time())
+	})
+	if diff := cmp.Diff(want, got, timeEqual); diff != "" {
+		t.Errorf("unexpected params:\n%s", diff)
+	}
+}
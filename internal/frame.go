@@ -0,0 +1,304 @@
+package webscenario
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/chromedp/cdproto/cdp"
+	"github.com/chromedp/cdproto/dom"
+	"github.com/chromedp/cdproto/page"
+	"github.com/chromedp/cdproto/runtime"
+	"github.com/chromedp/chromedp"
+	"github.com/yuin/gopher-lua"
+)
+
+// findFrameScript finds a frame element by its name attribute, or by CSS selector.
+const findFrameScript = `function(q) {
+	const escaped = CSS.escape(q);
+	let found = this.querySelector('iframe[name="' + escaped + '"], frame[name="' + escaped + '"]');
+	if (!found) {
+		try {
+			found = this.querySelector(q);
+		} catch (e) {
+			// q is not a valid CSS selector.
+		}
+	}
+	return found && (found.tagName === 'IFRAME' || found.tagName === 'FRAME') ? [found] : [];
+}`
+
+// Frame is an accessor to the document in an iframe.
+// It refers to the frame by ID instead of the iframe element, because the element is replaced every time the frame navigates.
+type Frame struct {
+	name string
+	id   cdp.FrameID
+	tab  *Tab
+}
+
+func NewFrame(L *lua.LState, t *Tab, query string) Frame {
+	name := fmt.Sprintf("$:frame(%q)", strings.TrimSpace(query))
+
+	var node *cdp.Node
+	var id cdp.FrameID
+	t.RunSelector(
+		L,
+		name,
+		nodeAction(query, &node, byJS(findFrameScript, query)),
+		chromedp.ActionFunc(func(ctx context.Context) error {
+			id = node.FrameID
+			if id == "" {
+				n, err := dom.DescribeNode().WithNodeID(node.NodeID).Do(ctx)
+				if err != nil {
+					return err
+				}
+				id = n.FrameID
+			}
+			if id == "" {
+				return errors.New("the frame is not loaded")
+			}
+			return nil
+		}),
+	)
+
+	return Frame{
+		name: name,
+		id:   id,
+		tab:  t,
+	}
+}
+
+// NewFramesTable makes a list of all frames in the tab, including nested frames.
+func NewFramesTable(L *lua.LState, t *Tab) *lua.LTable {
+	var tree *page.FrameTree
+	t.Run(L, "$.frames", false, 0, chromedp.ActionFunc(func(ctx context.Context) (err error) {
+		tree, err = page.GetFrameTree().Do(ctx)
+		return err
+	}))
+
+	tbl := L.NewTable()
+	var walk func(*page.FrameTree)
+	walk = func(tree *page.FrameTree) {
+		for _, child := range tree.ChildFrames {
+			tbl.Append(Frame{
+				name: fmt.Sprintf("$.frames[%d]", tbl.Len()+1),
+				id:   child.Frame.ID,
+				tab:  t,
+			}.ToLua(L))
+			walk(child)
+		}
+	}
+	if tree != nil {
+		walk(tree)
+	}
+	return tbl
+}
+
+func (f Frame) ToLua(L *lua.LState) *lua.LUserData {
+	ud := L.NewUserData()
+	ud.Value = f
+	L.SetMetatable(ud, L.GetTypeMetatable("frame"))
+	return ud
+}
+
+func CheckFrame(L *lua.LState) Frame {
+	if ud, ok := L.Get(1).(*lua.LUserData); ok {
+		if f, ok := ud.Value.(Frame); ok {
+			return f
+		}
+	}
+
+	L.ArgError(1, "frame expected. perhaps you call it like frame.xxx() instead of frame:xxx().")
+	return Frame{}
+}
+
+// document returns the backend node ID of the document in the frame.
+// It is found via the DOM domain instead of `contentDocument` in JavaScript, so that it works for cross-origin frames as well.
+func (f Frame) document(ctx context.Context) (cdp.BackendNodeID, error) {
+	backendID, _, err := dom.GetFrameOwner(f.id).Do(ctx)
+	if err != nil {
+		return 0, err
+	}
+
+	owner, err := dom.DescribeNode().WithBackendNodeID(backendID).WithPierce(true).Do(ctx)
+	if err != nil {
+		return 0, err
+	}
+	if owner.ContentDocument == nil {
+		return 0, errors.New("frame is not accessible")
+	}
+	return owner.ContentDocument.BackendNodeID, nil
+}
+
+// call makes an action to call a JavaScript function with `this` as the document of the frame.
+// The function runs in the execution context of the frame, not of the tab's page.
+func (f Frame) call(function string, res any, args ...any) chromedp.ActionFunc {
+	return func(ctx context.Context) error {
+		doc, err := f.document(ctx)
+		if err != nil {
+			return err
+		}
+		return callOn(dom.ResolveNode().WithBackendNodeID(doc), function, res, args...).Do(ctx)
+	}
+}
+
+// by is a query option to find nodes in the frame by a JavaScript function.
+// The function is called with `this` as the document of the frame, and should return an array of nodes.
+func (f Frame) by(function string, args ...any) chromedp.QueryOption {
+	return chromedp.ByFunc(func(ctx context.Context, _ *cdp.Node) ([]cdp.NodeID, error) {
+		var arr *runtime.RemoteObject
+		if err := f.call(function, &arr, args...).Do(ctx); err != nil {
+			return nil, err
+		}
+		return requestNodes(ctx, arr)
+	})
+}
+
+func (f Frame) byQuery(query string, all bool) chromedp.QueryOption {
+	return f.by(pierceScript, query, all)
+}
+
+func (f Frame) Select(L *lua.LState, query string) Element {
+	name := fmt.Sprintf("%s(%q)", f.name, strings.TrimSpace(query))
+	locate := locateNode(query, f.byQuery(query, false))
+
+	var node *cdp.Node
+	f.tab.RunSelector(L, name, chromedp.ActionFunc(func(ctx context.Context) (err error) {
+		node, err = locate(ctx)
+		return err
+	}))
+
+	return newElement(f.tab, name, node, locate)
+}
+
+func (f Frame) SelectAll(L *lua.LState, query string) *lua.LTable {
+	name := fmt.Sprintf("%s:all(%q)", f.name, strings.TrimSpace(query))
+	return newElementsTable(L, f.tab, name, locateNodes(query, f.byQuery(query, true), chromedp.AtLeast(0)))
+}
+
+func (f Frame) SelectByXPath(L *lua.LState, query string) *lua.LTable {
+	name := fmt.Sprintf("%s:xpath(%q)", f.name, strings.TrimSpace(query))
+	return newElementsTable(L, f.tab, name, locateNodes(query, f.by(`function(q) {
+		const r = this.evaluate(q, this, null, XPathResult.ORDERED_NODE_SNAPSHOT_TYPE, null);
+		const nodes = [];
+		for (let i = 0; i < r.snapshotLength; i++) {
+			nodes.push(r.snapshotItem(i));
+		}
+		return nodes;
+	}`, query)))
+}
+
+func (f Frame) Wait(L *lua.LState) {
+	query := L.CheckString(2)
+	timeout := time.Duration(float64(L.OptNumber(3, 0)) * float64(time.Millisecond))
+
+	f.tab.Run(L, fmt.Sprintf("%s:wait(%q)", f.name, query), true, timeout, chromedp.WaitReady(query, f.byQuery(query, false)))
+}
+
+func (f Frame) Eval(L *lua.LState) int {
+	script := L.CheckString(2)
+
+	var res any
+	f.tab.Run(
+		L,
+		fmt.Sprintf("%s:eval([[ %s ]])", f.name, script),
+		true,
+		0,
+		// Indirect eval runs the script in the global scope of the frame.
+		f.call(`function(script) { return (0, eval)(script) }`, &res, script),
+	)
+	L.Push(PackLValue(L, res))
+	return 1
+}
+
+func (f Frame) getInfo(L *lua.LState, property string) *cdp.Frame {
+	var frame *cdp.Frame
+	f.tab.Run(L, fmt.Sprintf("%s.%s", f.name, property), false, 0, chromedp.ActionFunc(func(ctx context.Context) error {
+		tree, err := page.GetFrameTree().Do(ctx)
+		if err != nil {
+			return err
+		}
+
+		var find func(*page.FrameTree) bool
+		find = func(tree *page.FrameTree) bool {
+			if tree.Frame.ID == f.id {
+				frame = tree.Frame
+				return true
+			}
+			for _, child := range tree.ChildFrames {
+				if find(child) {
+					return true
+				}
+			}
+			return false
+		}
+		if !find(tree) {
+			return errors.New("frame is detached")
+		}
+		return nil
+	}))
+	return frame
+}
+
+func (f Frame) GetName(L *lua.LState) int {
+	L.Push(lua.LString(f.getInfo(L, "name").Name))
+	return 1
+}
+
+func (f Frame) GetURL(L *lua.LState) int {
+	frame := f.getInfo(L, "url")
+	L.Push(lua.LString(frame.URL + frame.URLFragment))
+	return 1
+}
+
+func RegisterFrameType(ctx context.Context, L *lua.LState) {
+	methods := map[string]*lua.LFunction{
+		"all": L.NewFunction(func(L *lua.LState) int {
+			f := CheckFrame(L)
+			L.Push(f.SelectAll(L, L.CheckString(2)))
+			return 1
+		}),
+		"xpath": L.NewFunction(func(L *lua.LState) int {
+			f := CheckFrame(L)
+			L.Push(f.SelectByXPath(L, L.CheckString(2)))
+			return 1
+		}),
+		"wait": L.NewFunction(func(L *lua.LState) int {
+			CheckFrame(L).Wait(L)
+			L.Push(L.Get(1))
+			return 1
+		}),
+		"eval": L.NewFunction(func(L *lua.LState) int {
+			return CheckFrame(L).Eval(L)
+		}),
+	}
+
+	getters := map[string]func(Frame, *lua.LState) int{
+		"name": Frame.GetName,
+		"url":  Frame.GetURL,
+	}
+
+	L.SetFuncs(L.NewTypeMetatable("frame"), map[string]lua.LGFunction{
+		"__call": func(L *lua.LState) int {
+			f := CheckFrame(L)
+			L.Push(f.Select(L, L.CheckString(2)).ToLua(L))
+			return 1
+		},
+		"__index": func(L *lua.LState) int {
+			name := L.CheckString(2)
+
+			if f, ok := getters[name]; ok {
+				return f(CheckFrame(L), L)
+			} else if f, ok := methods[name]; ok {
+				L.Push(f)
+				return 1
+			}
+			return 0
+		},
+		"__tostring": func(L *lua.LState) int {
+			L.Push(lua.LString(CheckFrame(L).name))
+			return 1
+		},
+	})
+}
@@ -0,0 +1,318 @@
+package webscenario
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+	"unicode/utf8"
+
+	"github.com/chromedp/cdproto/network"
+)
+
+type harNameValue struct {
+	Name  string `json:"name"`
+	Value string `json:"value"`
+}
+
+type harPostData struct {
+	MimeType string `json:"mimeType"`
+	Text     string `json:"text"`
+}
+
+type harRequest struct {
+	Method      string         `json:"method"`
+	URL         string         `json:"url"`
+	HTTPVersion string         `json:"httpVersion"`
+	Cookies     []harNameValue `json:"cookies"`
+	Headers     []harNameValue `json:"headers"`
+	QueryString []harNameValue `json:"queryString"`
+	PostData    *harPostData   `json:"postData,omitempty"`
+	HeadersSize int64          `json:"headersSize"`
+	BodySize    int64          `json:"bodySize"`
+}
+
+type harContent struct {
+	Size     int64  `json:"size"`
+	MimeType string `json:"mimeType"`
+	Text     string `json:"text,omitempty"`
+	Encoding string `json:"encoding,omitempty"`
+}
+
+type harResponse struct {
+	Status      int64          `json:"status"`
+	StatusText  string         `json:"statusText"`
+	HTTPVersion string         `json:"httpVersion"`
+	Cookies     []harNameValue `json:"cookies"`
+	Headers     []harNameValue `json:"headers"`
+	Content     harContent     `json:"content"`
+	RedirectURL string         `json:"redirectURL"`
+	HeadersSize int64          `json:"headersSize"`
+	BodySize    int64          `json:"bodySize"`
+	Error       string         `json:"_error,omitempty"`
+}
+
+type harTimings struct {
+	Blocked float64 `json:"blocked"`
+	DNS     float64 `json:"dns"`
+	Connect float64 `json:"connect"`
+	Send    float64 `json:"send"`
+	Wait    float64 `json:"wait"`
+	Receive float64 `json:"receive"`
+	SSL     float64 `json:"ssl"`
+}
+
+type harEntry struct {
+	StartedDateTime string      `json:"startedDateTime"`
+	Time            float64     `json:"time"`
+	Request         harRequest  `json:"request"`
+	Response        harResponse `json:"response"`
+	Cache           struct{}    `json:"cache"`
+	Timings         harTimings  `json:"timings"`
+	ServerIPAddress string      `json:"serverIPAddress,omitempty"`
+	Connection      string      `json:"connection,omitempty"`
+	ResourceType    string      `json:"_resourceType,omitempty"`
+}
+
+type harCreator struct {
+	Name    string `json:"name"`
+	Version string `json:"version"`
+}
+
+type harLog struct {
+	Version string     `json:"version"`
+	Creator harCreator `json:"creator"`
+	Pages   []struct{} `json:"pages"`
+	Entries []harEntry `json:"entries"`
+}
+
+type harRecord struct {
+	id       network.RequestID
+	request  *network.Request
+	started  time.Time
+	typ      network.ResourceType
+	response *network.Response
+	finished float64
+	length   float64
+	err      string
+}
+
+// HARRecorder collects network events to make HTTP Archive.
+type HARRecorder struct {
+	sync.Mutex
+
+	body    bool
+	records []*harRecord
+	pending map[network.RequestID]*harRecord
+}
+
+func NewHARRecorder(body bool) *HARRecorder {
+	return &HARRecorder{
+		body:    body,
+		pending: make(map[network.RequestID]*harRecord),
+	}
+}
+
+// Handle records a network event.
+func (h *HARRecorder) Handle(ev any) {
+	h.Lock()
+	defer h.Unlock()
+
+	switch e := ev.(type) {
+	case *network.EventRequestWillBeSent:
+		if r, ok := h.pending[e.RequestID]; ok && e.RedirectResponse != nil {
+			r.response = e.RedirectResponse
+			r.finished = monotonicSeconds(e.Timestamp)
+			r.length = e.RedirectResponse.EncodedDataLength
+			delete(h.pending, e.RequestID)
+		}
+		var started time.Time
+		if e.WallTime != nil {
+			started = e.WallTime.Time()
+		}
+		r := &harRecord{
+			id:      e.RequestID,
+			request: e.Request,
+			started: started,
+			typ:     e.Type,
+		}
+		h.records = append(h.records, r)
+		h.pending[e.RequestID] = r
+	case *network.EventResponseReceived:
+		if r, ok := h.pending[e.RequestID]; ok {
+			r.response = e.Response
+		}
+	case *network.EventLoadingFinished:
+		if r, ok := h.pending[e.RequestID]; ok {
+			r.finished = monotonicSeconds(e.Timestamp)
+			r.length = e.EncodedDataLength
+		}
+	case *network.EventLoadingFailed:
+		if r, ok := h.pending[e.RequestID]; ok {
+			r.finished = monotonicSeconds(e.Timestamp)
+			r.err = e.ErrorText
+			delete(h.pending, e.RequestID)
+		}
+	}
+}
+
+func harHeaders(h network.Headers) []harNameValue {
+	xs := []harNameValue{}
+	for k, v := range h {
+		for _, s := range strings.Split(fmt.Sprint(v), "\n") {
+			xs = append(xs, harNameValue{k, s})
+		}
+	}
+	sort.Slice(xs, func(i, j int) bool {
+		return xs[i].Name < xs[j].Name
+	})
+	return xs
+}
+
+func harQueryString(u string) []harNameValue {
+	xs := []harNameValue{}
+	if parsed, err := url.Parse(u); err == nil {
+		for k, vs := range parsed.Query() {
+			for _, v := range vs {
+				xs = append(xs, harNameValue{k, v})
+			}
+		}
+	}
+	sort.Slice(xs, func(i, j int) bool {
+		return xs[i].Name < xs[j].Name
+	})
+	return xs
+}
+
+func harHTTPVersion(protocol string) string {
+	switch strings.ToLower(protocol) {
+	case "":
+		return ""
+	case "h2":
+		return "HTTP/2.0"
+	case "h3":
+		return "HTTP/3.0"
+	default:
+		return strings.ToUpper(protocol)
+	}
+}
+
+func (r harRecord) entry(ctx context.Context, body bool) harEntry {
+	req := r.request
+	e := harEntry{
+		StartedDateTime: r.started.Format(time.RFC3339Nano),
+		Request: harRequest{
+			Method:      req.Method,
+			URL:         req.URL + req.URLFragment,
+			Cookies:     []harNameValue{},
+			Headers:     harHeaders(req.Headers),
+			QueryString: harQueryString(req.URL),
+			HeadersSize: -1,
+			BodySize:    0,
+		},
+		Response: harResponse{
+			Cookies:     []harNameValue{},
+			Headers:     []harNameValue{},
+			HeadersSize: -1,
+			BodySize:    -1,
+			Error:       r.err,
+		},
+		ResourceType: strings.ToLower(r.typ.String()),
+	}
+
+	if req.HasPostData {
+		mimeType := ""
+		if v, ok := req.Headers["Content-Type"]; ok {
+			mimeType = fmt.Sprint(v)
+		}
+		e.Request.PostData = &harPostData{MimeType: mimeType, Text: req.PostData}
+		e.Request.BodySize = int64(len(req.PostData))
+	}
+
+	res := r.response
+	if res == nil {
+		e.Timings = harTimings{Blocked: -1, DNS: -1, Connect: -1, Send: 0, Wait: 0, Receive: 0, SSL: -1}
+		return e
+	}
+
+	e.Request.HTTPVersion = harHTTPVersion(res.Protocol)
+	e.Response.Status = res.Status
+	e.Response.StatusText = res.StatusText
+	e.Response.HTTPVersion = harHTTPVersion(res.Protocol)
+	e.Response.Headers = harHeaders(res.Headers)
+	e.Response.BodySize = int64(r.length)
+	e.Response.Content = harContent{
+		Size:     int64(r.length),
+		MimeType: res.MimeType,
+	}
+	if v, ok := res.Headers["Location"]; ok {
+		e.Response.RedirectURL = fmt.Sprint(v)
+	}
+	if res.RemoteIPAddress != "" {
+		e.ServerIPAddress = strings.Trim(res.RemoteIPAddress, "[]")
+	}
+	if res.ConnectionID > 0 {
+		e.Connection = strconv.FormatFloat(res.ConnectionID, 'f', -1, 64)
+	}
+
+	rt := CalcResourceTimings(res.Timing, r.finished)
+	e.Timings = harTimings{
+		Blocked: rt.Blocked,
+		DNS:     rt.DNS,
+		Connect: rt.Connect,
+		SSL:     rt.SSL,
+		Send:    nonNegative(rt.Send),
+		Wait:    nonNegative(rt.Wait),
+		Receive: nonNegative(rt.Receive),
+	}
+	e.Time = rt.Total()
+
+	if body && r.err == "" && r.finished > 0 && res.Status/100 != 3 {
+		if buf, err := network.GetResponseBody(r.id).Do(ctx); err == nil {
+			e.Response.Content.Size = int64(len(buf))
+			if utf8.Valid(buf) {
+				e.Response.Content.Text = string(buf)
+			} else {
+				e.Response.Content.Text = base64.StdEncoding.EncodeToString(buf)
+				e.Response.Content.Encoding = "base64"
+			}
+		}
+	}
+
+	return e
+}
+
+// Build makes HTTP Archive in JSON.
+// The ctx is used to retrieve response bodies.
+func (h *HARRecorder) Build(ctx context.Context) ([]byte, error) {
+	// Records are copied because the HARRecorder can't be locked while retrieving response bodies.
+	h.Lock()
+	records := make([]harRecord, len(h.records))
+	for i, r := range h.records {
+		records[i] = *r
+	}
+	body := h.body
+	h.Unlock()
+
+	log := harLog{
+		Version: "1.2",
+		Creator: harCreator{
+			Name:    "ayd-web-scenario",
+			Version: Version,
+		},
+		Pages:   []struct{}{},
+		Entries: make([]harEntry, len(records)),
+	}
+
+	for i, r := range records {
+		log.Entries[i] = r.entry(ctx, body)
+	}
+
+	return json.MarshalIndent(map[string]any{"log": log}, "", "  ")
+}
@@ -0,0 +1,148 @@
+package webscenario
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/chromedp/cdproto/cdp"
+	"github.com/chromedp/cdproto/network"
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestHARRecorder(t *testing.T) {
+	monotonic := func(sec float64) *cdp.MonotonicTime {
+		t := cdp.MonotonicTime(cdp.MonotonicTimeEpoch.Add(time.Duration(sec * float64(time.Second))))
+		return &t
+	}
+	wall := cdp.TimeSinceEpoch(time.Date(2023, 4, 1, 12, 0, 0, 0, time.UTC))
+
+	h := NewHARRecorder(false)
+
+	h.Handle(&network.EventRequestWillBeSent{
+		RequestID: "1",
+		Request: &network.Request{
+			URL:         "http://example.com/search?q=hello",
+			URLFragment: "#top",
+			Method:      "POST",
+			Headers:     network.Headers{"Content-Type": "application/x-www-form-urlencoded"},
+			HasPostData: true,
+			PostData:    "a=b",
+		},
+		Timestamp: monotonic(100),
+		WallTime:  &wall,
+		Type:      network.ResourceTypeDocument,
+	})
+	h.Handle(&network.EventResponseReceived{
+		RequestID: "1",
+		Response: &network.Response{
+			URL:             "http://example.com/search?q=hello",
+			Status:          200,
+			StatusText:      "OK",
+			Headers:         network.Headers{"Content-Type": "text/html"},
+			MimeType:        "text/html",
+			RemoteIPAddress: "[::1]",
+			Protocol:        "h2",
+			Timing: &network.ResourceTiming{
+				RequestTime:       100,
+				DNSStart:          -1,
+				DNSEnd:            -1,
+				ConnectStart:      -1,
+				ConnectEnd:        -1,
+				SslStart:          -1,
+				SslEnd:            -1,
+				SendStart:         1,
+				SendEnd:           2,
+				ReceiveHeadersEnd: 10,
+			},
+		},
+	})
+	h.Handle(&network.EventLoadingFinished{
+		RequestID:         "1",
+		Timestamp:         monotonic(100.02),
+		EncodedDataLength: 123,
+	})
+
+	h.Handle(&network.EventRequestWillBeSent{
+		RequestID: "2",
+		Request: &network.Request{
+			URL:    "http://example.com/missing.js",
+			Method: "GET",
+		},
+		Timestamp: monotonic(101),
+		WallTime:  &wall,
+		Type:      network.ResourceTypeScript,
+	})
+	h.Handle(&network.EventLoadingFailed{
+		RequestID: "2",
+		Timestamp: monotonic(101.5),
+		ErrorText: "net::ERR_CONNECTION_REFUSED",
+	})
+
+	buf, err := h.Build(context.Background())
+	if err != nil {
+		t.Fatalf("failed to build HAR: %s", err)
+	}
+
+	var har struct {
+		Log harLog `json:"log"`
+	}
+	if err := json.Unmarshal(buf, &har); err != nil {
+		t.Fatalf("failed to parse HAR: %s", err)
+	}
+
+	if har.Log.Version != "1.2" {
+		t.Errorf("unexpected version: %q", har.Log.Version)
+	}
+	if len(har.Log.Entries) != 2 {
+		t.Fatalf("unexpected number of entries: %d", len(har.Log.Entries))
+	}
+
+	e := har.Log.Entries[0]
+	if e.StartedDateTime != "2023-04-01T12:00:00Z" {
+		t.Errorf("unexpected startedDateTime: %q", e.StartedDateTime)
+	}
+	if diff := cmp.Diff(harRequest{
+		Method:      "POST",
+		URL:         "http://example.com/search?q=hello#top",
+		HTTPVersion: "HTTP/2.0",
+		Cookies:     []harNameValue{},
+		Headers:     []harNameValue{{"Content-Type", "application/x-www-form-urlencoded"}},
+		QueryString: []harNameValue{{"q", "hello"}},
+		PostData:    &harPostData{MimeType: "application/x-www-form-urlencoded", Text: "a=b"},
+		HeadersSize: -1,
+		BodySize:    3,
+	}, e.Request); diff != "" {
+		t.Errorf("request: %s", diff)
+	}
+	if diff := cmp.Diff(harResponse{
+		Status:      200,
+		StatusText:  "OK",
+		HTTPVersion: "HTTP/2.0",
+		Cookies:     []harNameValue{},
+		Headers:     []harNameValue{{"Content-Type", "text/html"}},
+		Content:     harContent{Size: 123, MimeType: "text/html"},
+		HeadersSize: -1,
+		BodySize:    123,
+	}, e.Response); diff != "" {
+		t.Errorf("response: %s", diff)
+	}
+	if e.ServerIPAddress != "::1" {
+		t.Errorf("unexpected serverIPAddress: %q", e.ServerIPAddress)
+	}
+	if int(e.Time+0.5) != 20 {
+		t.Errorf("unexpected time: %f", e.Time)
+	}
+
+	e = har.Log.Entries[1]
+	if e.Response.Error != "net::ERR_CONNECTION_REFUSED" {
+		t.Errorf("unexpected error: %q", e.Response.Error)
+	}
+	if e.Response.Status != 0 {
+		t.Errorf("unexpected status: %d", e.Response.Status)
+	}
+	if e.ResourceType != "script" {
+		t.Errorf("unexpected resource type: %q", e.ResourceType)
+	}
+}
@@ -0,0 +1,161 @@
+package webscenario
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/chromedp/cdproto/accessibility"
+	"github.com/chromedp/cdproto/cdp"
+	"github.com/chromedp/cdproto/dom"
+	"github.com/chromedp/chromedp"
+	"github.com/yuin/gopher-lua"
+)
+
+// textMatcherScript defines `matches` function that checks if a string matches to `text`, in JavaScript.
+// It ignores differences of whitespaces, and does case-insensitive substring matching unless `exact` is true.
+const textMatcherScript = `
+	const normalize = (s) => s.replace(/\s+/g, ' ').trim();
+	const want = exact ? normalize(text) : normalize(text).toLowerCase();
+	const matches = (s) => exact ? normalize(s) === want : normalize(s).toLowerCase().includes(want);
+`
+
+// byTextScript finds the innermost elements that contain the text.
+const byTextScript = `function(text, exact) {` + textMatcherScript + `
+	const ignored = ['HEAD', 'SCRIPT', 'STYLE', 'NOSCRIPT', 'TEMPLATE'];
+	const buttons = ['button', 'submit', 'reset'];
+	const found = [];
+	const walk = (e) => {
+		if (ignored.includes(e.tagName)) {
+			return false;
+		}
+		let inner = false;
+		for (const c of e.children) {
+			inner = walk(c) || inner;
+		}
+		if (inner) {
+			return true;
+		}
+		const content = e.tagName === 'INPUT' && buttons.includes(e.type) ? e.value : e.textContent;
+		if (matches(content)) {
+			found.push(e);
+			return true;
+		}
+		return false;
+	};
+	for (const c of this.children) {
+		walk(c);
+	}
+	return found;
+}`
+
+// byLabelScript finds elements that labeled by the text, via label element, aria-label, or aria-labelledby.
+const byLabelScript = `function(text, exact) {` + textMatcherScript + `
+	const doc = this.ownerDocument || this;
+	const found = [];
+	for (const e of this.querySelectorAll('*')) {
+		const labels = e.labels ? Array.from(e.labels, (l) => l.textContent) : [];
+		if (e.hasAttribute('aria-label')) {
+			labels.push(e.getAttribute('aria-label'));
+		}
+		if (e.hasAttribute('aria-labelledby')) {
+			labels.push(e.getAttribute('aria-labelledby').split(/\s+/).map((id) => {
+				const l = doc.getElementById(id);
+				return l ? l.textContent : '';
+			}).join(' '));
+		}
+		if (labels.some(matches)) {
+			found.push(e);
+		}
+	}
+	return found;
+}`
+
+// byRole is a query option to find elements by ARIA role and accessible name, using the accessibility tree.
+func byRole(role, name string) chromedp.QueryOption {
+	return chromedp.ByFunc(func(ctx context.Context, n *cdp.Node) ([]cdp.NodeID, error) {
+		q := accessibility.QueryAXTree().WithNodeID(n.NodeID).WithRole(role)
+		if name != "" {
+			q = q.WithAccessibleName(name)
+		}
+		axs, err := q.Do(ctx)
+		if err != nil {
+			return nil, err
+		}
+
+		var ids []cdp.BackendNodeID
+		for _, ax := range axs {
+			if !ax.Ignored && ax.BackendDOMNodeID != 0 {
+				ids = append(ids, ax.BackendDOMNodeID)
+			}
+		}
+		if len(ids) == 0 {
+			return nil, nil
+		}
+		return dom.PushNodesByBackendIDsToFrontend(ids).Do(ctx)
+	})
+}
+
+// checkExactOption parses an option table that has `exact` field.
+func checkExactOption(L *lua.LState, n int) (exact bool, desc string) {
+	switch v := L.Get(n).(type) {
+	case *lua.LNilType:
+	case *lua.LTable:
+		exact = lua.LVAsBool(L.GetField(v, "exact"))
+	default:
+		L.ArgError(n, "a nil or a table expected.")
+	}
+	if exact {
+		desc = ", {exact=true}"
+	}
+	return exact, desc
+}
+
+func (t *Tab) GetByText(L *lua.LState) int {
+	text := L.CheckString(2)
+	exact, desc := checkExactOption(L, 3)
+
+	name := fmt.Sprintf("$:getByText(%q%s)", text, desc)
+	L.Push(NewElementBy(L, t, name, text, byJS(byTextScript, text, exact)).ToLua(L))
+	return 1
+}
+
+func (t *Tab) GetByLabel(L *lua.LState) int {
+	label := L.CheckString(2)
+	exact, desc := checkExactOption(L, 3)
+
+	name := fmt.Sprintf("$:getByLabel(%q%s)", label, desc)
+	L.Push(NewElementBy(L, t, name, label, byJS(byLabelScript, label, exact)).ToLua(L))
+	return 1
+}
+
+func (t *Tab) GetByRole(L *lua.LState) int {
+	role := L.CheckString(2)
+
+	accessibleName := ""
+	switch v := L.Get(3).(type) {
+	case *lua.LNilType:
+	case *lua.LTable:
+		if s, ok := L.GetField(v, "name").(lua.LString); ok {
+			accessibleName = string(s)
+		}
+	default:
+		L.ArgError(3, "a nil or a table expected.")
+	}
+
+	name := fmt.Sprintf("$:getByRole(%q)", role)
+	if accessibleName != "" {
+		name = fmt.Sprintf("$:getByRole(%q, {name=%q})", role, accessibleName)
+	}
+	L.Push(NewElementBy(L, t, name, role, byRole(role, accessibleName)).ToLua(L))
+	return 1
+}
+
+func (t *Tab) GetByTestID(L *lua.LState) int {
+	id := L.CheckString(2)
+
+	name := fmt.Sprintf("$:getByTestId(%q)", id)
+	L.Push(NewElementBy(L, t, name, id, byJS(`function(id) {
+		return Array.from(this.querySelectorAll('[data-testid="' + CSS.escape(id) + '"]'));
+	}`, id)).ToLua(L))
+	return 1
+}
@@ -0,0 +1,103 @@
+package webscenario
+
+import (
+	"context"
+	"encoding/json"
+
+	"github.com/chromedp/cdproto/performance"
+	"github.com/chromedp/cdproto/runtime"
+)
+
+// metricsScript collects Navigation Timing, paint timings, and Core Web Vitals from the page.
+// All times are milliseconds since the navigation started.
+const metricsScript = `(async () => {
+	const observe = async (type) => {
+		const entries = [];
+		try {
+			const po = new PerformanceObserver((list) => entries.push(...list.getEntries()));
+			po.observe({type, buffered: true});
+			await new Promise((resolve) => setTimeout(resolve, 0));
+			entries.push(...po.takeRecords());
+			po.disconnect();
+		} catch (e) {
+			// This browser doesn't support the entry type.
+		}
+		return entries;
+	};
+
+	const nav = performance.getEntriesByType('navigation')[0];
+	const fcp = performance.getEntriesByName('first-contentful-paint')[0];
+	const lcp = (await observe('largest-contentful-paint')).pop();
+
+	let cls = 0, session = 0, first = 0, last = 0;
+	for (const e of await observe('layout-shift')) {
+		if (e.hadRecentInput) continue;
+		if (session > 0 && e.startTime - last < 1000 && e.startTime - first < 5000) {
+			session += e.value;
+		} else {
+			session = e.value;
+			first = e.startTime;
+		}
+		last = e.startTime;
+		cls = Math.max(cls, session);
+	}
+
+	let tbt = 0;
+	for (const e of await observe('longtask')) {
+		if (fcp && e.startTime < fcp.startTime) continue;
+		tbt += Math.max(0, e.duration - 50);
+	}
+
+	return {
+		navigation: nav ? {
+			redirect: nav.redirectEnd - nav.redirectStart,
+			dns: nav.domainLookupEnd - nav.domainLookupStart,
+			connect: nav.connectEnd - nav.connectStart,
+			ttfb: nav.responseStart,
+			response: nav.responseEnd - nav.responseStart,
+			domInteractive: nav.domInteractive,
+			domContentLoaded: nav.domContentLoadedEventEnd,
+			load: nav.loadEventEnd,
+			transferSize: nav.transferSize,
+		} : null,
+		fcp: fcp ? fcp.startTime : null,
+		lcp: lcp ? lcp.startTime : null,
+		cls: cls,
+		tbt: tbt,
+	};
+})()`
+
+// CollectMetrics collects web performance metrics of the current page, and counters of the browser such as JS heap size.
+func CollectMetrics(ctx context.Context) (map[string]any, error) {
+	res, exp, err := runtime.Evaluate(metricsScript).
+		WithAwaitPromise(true).
+		WithReturnByValue(true).
+		Do(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if exp != nil {
+		return nil, exp
+	}
+
+	metrics := make(map[string]any)
+	if err := json.Unmarshal(res.Value, &metrics); err != nil {
+		return nil, err
+	}
+
+	if err := performance.Enable().Do(ctx); err != nil {
+		return nil, err
+	}
+	ms, err := performance.GetMetrics().Do(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	counters := make(map[string]any)
+	for _, m := range ms {
+		counters[m.Name] = m.Value
+	}
+	metrics["counters"] = counters
+
+	return metrics, nil
+}
@@ -0,0 +1,154 @@
+package webscenario
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	"github.com/chromedp/cdproto/cdp"
+	"github.com/chromedp/cdproto/dom"
+	"github.com/chromedp/cdproto/input"
+	"github.com/chromedp/chromedp"
+	"github.com/yuin/gopher-lua"
+)
+
+// Mouse keeps the pointer state of a tab, so that each mouse event continues from the previous one.
+type Mouse struct {
+	sync.Mutex
+
+	x, y    float64
+	pressed bool
+}
+
+func (m *Mouse) dispatch(ctx context.Context, typ input.MouseType, x, y float64) error {
+	p := input.DispatchMouseEvent(typ, x, y)
+	switch typ {
+	case input.MousePressed:
+		p = p.WithButton(input.Left).WithButtons(1).WithClickCount(1)
+	case input.MouseReleased:
+		p = p.WithButton(input.Left).WithClickCount(1)
+	default:
+		if m.pressed {
+			p = p.WithButton(input.Left).WithButtons(1)
+		}
+	}
+	if err := p.Do(ctx); err != nil {
+		return err
+	}
+	m.x, m.y = x, y
+	m.pressed = typ == input.MousePressed || (m.pressed && typ != input.MouseReleased)
+	return nil
+}
+
+// Move makes an action to move the pointer to (x, y), dispatching mousemove events in the given number of steps.
+func (m *Mouse) Move(x, y float64, steps int) chromedp.ActionFunc {
+	return func(ctx context.Context) error {
+		m.Lock()
+		defer m.Unlock()
+
+		fromX, fromY := m.x, m.y
+		for i := 1; i <= steps; i++ {
+			r := float64(i) / float64(steps)
+			if err := m.dispatch(ctx, input.MouseMoved, fromX+(x-fromX)*r, fromY+(y-fromY)*r); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+}
+
+// Press makes an action to press or release the left button at the current position.
+func (m *Mouse) Press(pressed bool) chromedp.ActionFunc {
+	return func(ctx context.Context) error {
+		m.Lock()
+		defer m.Unlock()
+
+		typ := input.MouseReleased
+		if pressed {
+			typ = input.MousePressed
+		}
+		return m.dispatch(ctx, typ, m.x, m.y)
+	}
+}
+
+// Wheel makes an action to rotate the mouse wheel at the current position.
+func (m *Mouse) Wheel(deltaX, deltaY float64) chromedp.ActionFunc {
+	return func(ctx context.Context) error {
+		m.Lock()
+		defer m.Unlock()
+
+		return input.DispatchMouseEvent(input.MouseWheel, m.x, m.y).
+			WithDeltaX(deltaX).
+			WithDeltaY(deltaY).
+			Do(ctx)
+	}
+}
+
+// MouseActions is a map of action names for tab:mouse().
+var MouseActions = map[string]func(m *Mouse, x, y float64) chromedp.Action{
+	"move": func(m *Mouse, x, y float64) chromedp.Action {
+		return m.Move(x, y, 1)
+	},
+	"down": func(m *Mouse, x, y float64) chromedp.Action {
+		return chromedp.Tasks{m.Move(x, y, 1), m.Press(true)}
+	},
+	"up": func(m *Mouse, x, y float64) chromedp.Action {
+		return chromedp.Tasks{m.Move(x, y, 1), m.Press(false)}
+	},
+	"click": func(m *Mouse, x, y float64) chromedp.Action {
+		return chromedp.Tasks{m.Move(x, y, 1), m.Press(true), m.Press(false)}
+	},
+}
+
+// nodeCenter scrolls the node into view, and returns the center position of it in the viewport.
+func nodeCenter(ctx context.Context, node *cdp.Node) (x, y float64, err error) {
+	if err := dom.ScrollIntoViewIfNeeded().WithNodeID(node.NodeID).Do(ctx); err != nil {
+		return 0, 0, err
+	}
+
+	quads, err := dom.GetContentQuads().WithNodeID(node.NodeID).Do(ctx)
+	if err != nil {
+		return 0, 0, err
+	}
+	if len(quads) == 0 || len(quads[0]) < 2 || len(quads[0])%2 != 0 {
+		return 0, 0, chromedp.ErrInvalidDimensions
+	}
+
+	q := quads[0]
+	for i := 0; i < len(q); i += 2 {
+		x += q[i]
+		y += q[i+1]
+	}
+	n := float64(len(q) / 2)
+	return x / n, y / n, nil
+}
+
+// MoveToNode makes an action to move the pointer to the center of the node.
+func (m *Mouse) MoveToNode(node *cdp.Node, steps int) chromedp.ActionFunc {
+	return func(ctx context.Context) error {
+		x, y, err := nodeCenter(ctx, node)
+		if err != nil {
+			return err
+		}
+		return m.Move(x, y, steps).Do(ctx)
+	}
+}
+
+// CheckPoint checks if the argument is a table of a point, either {x=1, y=2} or {1, 2}.
+func CheckPoint(L *lua.LState, n int, tbl *lua.LTable) (x, y float64) {
+	vx, vy := L.GetField(tbl, "x"), L.GetField(tbl, "y")
+	if vx.Type() == lua.LTNil && vy.Type() == lua.LTNil {
+		vx, vy = tbl.RawGetInt(1), tbl.RawGetInt(2)
+	}
+
+	fx, okx := vx.(lua.LNumber)
+	fy, oky := vy.(lua.LNumber)
+	if !okx || !oky {
+		L.ArgError(n, "a table with x and y expected.")
+	}
+	return float64(fx), float64(fy)
+}
+
+func formatPoint(x, y float64) string {
+	return fmt.Sprintf("{x=%v, y=%v}", lua.LNumber(x), lua.LNumber(y))
+}
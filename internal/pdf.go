@@ -0,0 +1,138 @@
+package webscenario
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/chromedp/cdproto/page"
+	"github.com/yuin/gopher-lua"
+)
+
+// PaperSizes is a map of paper size presets in inches.
+var PaperSizes = map[string][2]float64{
+	"letter":  {8.5, 11},
+	"legal":   {8.5, 14},
+	"tabloid": {11, 17},
+	"ledger":  {17, 11},
+	"a0":      {33.1, 46.8},
+	"a1":      {23.4, 33.1},
+	"a2":      {16.54, 23.4},
+	"a3":      {11.7, 16.54},
+	"a4":      {8.27, 11.7},
+	"a5":      {5.83, 8.27},
+	"a6":      {4.13, 5.83},
+}
+
+func paperSizeNames() string {
+	var names []string
+	for k := range PaperSizes {
+		names = append(names, strconv.Quote(k))
+	}
+	sort.Strings(names)
+	return strings.Join(names, ", ")
+}
+
+var lengthUnits = map[string]float64{
+	"in": 1,
+	"cm": 1 / 2.54,
+	"mm": 1 / 25.4,
+	"px": 1.0 / 96,
+}
+
+// parseLength parses a length value of Lua into inches.
+// The value can be a number in inches, or a string with unit such as "10mm".
+func parseLength(v lua.LValue) (float64, bool) {
+	switch x := v.(type) {
+	case lua.LNumber:
+		return float64(x), x >= 0
+	case lua.LString:
+		s := strings.TrimSpace(string(x))
+		for unit, scale := range lengthUnits {
+			if strings.HasSuffix(s, unit) {
+				f, err := strconv.ParseFloat(strings.TrimSpace(s[:len(s)-len(unit)]), 64)
+				if err != nil || f < 0 {
+					return 0, false
+				}
+				return f * scale, true
+			}
+		}
+	}
+	return 0, false
+}
+
+// CheckPDFOptions makes parameters for page.PrintToPDF from a Lua table.
+func CheckPDFOptions(L *lua.LState, n int) *page.PrintToPDFParams {
+	// Margins have to be set explicitly because they are not omitted even if zero.
+	params := page.PrintToPDF()
+	params.MarginTop = 0.4
+	params.MarginBottom = 0.4
+	params.MarginLeft = 0.4
+	params.MarginRight = 0.4
+
+	tbl, ok := L.Get(n).(*lua.LTable)
+	if !ok {
+		if L.Get(n).Type() != lua.LTNil {
+			L.ArgError(n, "a nil or a table expected.")
+		}
+		return params
+	}
+
+	checkLength := func(name string, v lua.LValue) float64 {
+		f, ok := parseLength(v)
+		if !ok {
+			L.ArgError(n, fmt.Sprintf("%s field expected a number in inches or a string with unit such as \"10mm\".", name))
+		}
+		return f
+	}
+
+	switch p := L.GetField(tbl, "paper").(type) {
+	case *lua.LNilType:
+	case lua.LString:
+		size, ok := PaperSizes[strings.ToLower(string(p))]
+		if !ok {
+			L.ArgError(n, fmt.Sprintf("unknown paper size %q. please use one of %s, or a table with width and height.", p, paperSizeNames()))
+		}
+		params.PaperWidth, params.PaperHeight = size[0], size[1]
+	case *lua.LTable:
+		params.PaperWidth = checkLength("paper.width", L.GetField(p, "width"))
+		params.PaperHeight = checkLength("paper.height", L.GetField(p, "height"))
+	default:
+		L.ArgError(n, "paper field expected a string or a table.")
+	}
+
+	params.Landscape = lua.LVAsBool(L.GetField(tbl, "landscape"))
+	params.PrintBackground = lua.LVAsBool(L.GetField(tbl, "background"))
+
+	switch m := L.GetField(tbl, "margin").(type) {
+	case *lua.LNilType:
+	case *lua.LTable:
+		for _, x := range []struct {
+			name string
+			ptr  *float64
+		}{
+			{"top", &params.MarginTop},
+			{"bottom", &params.MarginBottom},
+			{"left", &params.MarginLeft},
+			{"right", &params.MarginRight},
+		} {
+			if v := L.GetField(m, x.name); v.Type() != lua.LTNil {
+				*x.ptr = checkLength("margin."+x.name, v)
+			}
+		}
+	default:
+		f := checkLength("margin", m)
+		params.MarginTop, params.MarginBottom, params.MarginLeft, params.MarginRight = f, f, f, f
+	}
+
+	if s, ok := L.GetField(tbl, "scale").(lua.LNumber); ok {
+		params.Scale = float64(s)
+	}
+
+	if r, ok := L.GetField(tbl, "pages").(lua.LString); ok {
+		params.PageRanges = string(r)
+	}
+
+	return params
+}
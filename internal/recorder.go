@@ -96,6 +96,13 @@ func (r *Recorder) runRecorder(ch <-chan recorderTask, width, height int) {
 			continue
 		}
 
+		if orig.Bounds().Size() != screenSize.Size() {
+			// The screenshot can be larger than the screen if the device scale factor is not 1.
+			scaled := image.NewRGBA(screenSize)
+			draw.ApproxBiLinear.Scale(scaled, screenSize, orig, orig.Bounds(), draw.Src, nil)
+			orig = scaled
+		}
+
 		img := image.NewPaletted(recordSize, Palette)
 		draw.FloydSteinberg.Draw(img, screenSize, orig, image.ZP)
 
@@ -0,0 +1,152 @@
+package webscenario
+
+import (
+	"encoding/base64"
+	"regexp"
+	"strings"
+	"sync"
+
+	"github.com/chromedp/cdproto/fetch"
+	"github.com/chromedp/cdproto/network"
+	"github.com/chromedp/chromedp"
+	"github.com/yuin/gopher-lua"
+)
+
+// globToRegexp converts an URL pattern of CDP Fetch domain to a regular expression.
+// The '*' means zero or more characters, the '?' means exactly one character, and the backslash escapes next character.
+func globToRegexp(pattern string) *regexp.Regexp {
+	var buf strings.Builder
+	buf.WriteString("^")
+
+	escaped := false
+	for _, c := range pattern {
+		switch {
+		case escaped:
+			buf.WriteString(regexp.QuoteMeta(string(c)))
+			escaped = false
+		case c == '\\':
+			escaped = true
+		case c == '*':
+			buf.WriteString(".*")
+		case c == '?':
+			buf.WriteString(".")
+		default:
+			buf.WriteString(regexp.QuoteMeta(string(c)))
+		}
+	}
+	if escaped {
+		buf.WriteString(regexp.QuoteMeta("\\"))
+	}
+
+	buf.WriteString("$")
+	return regexp.MustCompile(buf.String())
+}
+
+type route struct {
+	pattern string
+	re      *regexp.Regexp
+	lfunc   *lua.LFunction
+}
+
+type Router struct {
+	sync.Mutex
+
+	routes []route
+}
+
+func NewRouter() *Router {
+	return &Router{}
+}
+
+// Set registers a handler function for the pattern.
+// The handler for the same pattern will be replaced, and it will be removed if f is nil.
+func (r *Router) Set(pattern string, f *lua.LFunction) {
+	r.Lock()
+	defer r.Unlock()
+
+	for i, x := range r.routes {
+		if x.pattern == pattern {
+			if f == nil {
+				r.routes = append(r.routes[:i], r.routes[i+1:]...)
+			} else {
+				r.routes[i].lfunc = f
+			}
+			return
+		}
+	}
+
+	if f != nil {
+		r.routes = append(r.routes, route{
+			pattern: pattern,
+			re:      globToRegexp(pattern),
+			lfunc:   f,
+		})
+	}
+}
+
+func (r *Router) Patterns() []*fetch.RequestPattern {
+	r.Lock()
+	defer r.Unlock()
+
+	ps := make([]*fetch.RequestPattern, len(r.routes))
+	for i, x := range r.routes {
+		ps[i] = &fetch.RequestPattern{URLPattern: x.pattern}
+	}
+	return ps
+}
+
+// Find returns the first handler function that matches to the url.
+func (r *Router) Find(url string) *lua.LFunction {
+	r.Lock()
+	defer r.Unlock()
+
+	for _, x := range r.routes {
+		if x.re.MatchString(url) {
+			return x.lfunc
+		}
+	}
+	return nil
+}
+
+// UnpackRouteResult makes an action to resolve the paused request from the return values of a route handler.
+//
+// The handler can return nil to continue the request, false and an optional error reason to abort the request, or a table to fulfill the request.
+// It raises an error if the table is invalid.
+func UnpackRouteResult(L *lua.LState, id fetch.RequestID, result []lua.LValue) chromedp.Action {
+	switch v := result[0].(type) {
+	case lua.LBool:
+		if v == lua.LFalse {
+			reason := network.ErrorReasonBlockedByClient
+			if s, ok := result[1].(lua.LString); ok {
+				reason = network.ErrorReason(s)
+			}
+			return fetch.FailRequest(id, reason)
+		}
+	case *lua.LTable:
+		status := int64(200)
+		if s, ok := L.GetField(v, "status").(lua.LNumber); ok {
+			status = int64(s)
+		}
+		action := fetch.FulfillRequest(id, status)
+
+		h, err := UnpackFetchHeader(L, L.GetField(v, "headers"))
+		if err != nil {
+			L.ArgError(1, err.Error())
+		}
+		var headers []*fetch.HeaderEntry
+		for k, vs := range h {
+			for _, x := range vs {
+				headers = append(headers, &fetch.HeaderEntry{Name: k, Value: x})
+			}
+		}
+		action = action.WithResponseHeaders(headers)
+
+		if b := L.GetField(v, "body"); b.Type() != lua.LTNil {
+			action = action.WithBody(base64.StdEncoding.EncodeToString([]byte(L.ToStringMeta(b).String())))
+		}
+
+		return action
+	}
+
+	return fetch.ContinueRequest(id)
+}
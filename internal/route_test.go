@@ -0,0 +1,54 @@
+package webscenario
+
+import (
+	"testing"
+
+	"github.com/yuin/gopher-lua"
+)
+
+func TestGlobToRegexp(t *testing.T) {
+	tests := []struct {
+		pattern string
+		url     string
+		want    bool
+	}{
+		{"*", "https://example.com/", true},
+		{"*/api/*", "https://example.com/api/users", true},
+		{"*/api/*", "https://example.com/static/app.js", false},
+		{"https://example.com/?", "https://example.com/a", true},
+		{"https://example.com/?", "https://example.com/", false},
+		{"https://example.com/?", "https://example.com/ab", false},
+		{"*.png", "https://example.com/logo.png", true},
+		{"*.png", "https://example.com/logo.png?v=1", false},
+		{`*\?v=1`, "https://example.com/logo.png?v=1", true},
+		{`*\?v=1`, "https://example.com/logo.pngxv=1", false},
+		{`*\*`, "https://example.com/*", true},
+		{`*\*`, "https://example.com/a", false},
+	}
+
+	for _, tt := range tests {
+		if actual := globToRegexp(tt.pattern).MatchString(tt.url); actual != tt.want {
+			t.Errorf("%q on %q: expected %v but got %v", tt.pattern, tt.url, tt.want, actual)
+		}
+	}
+}
+
+func TestUnpackRouteResult_invalidHeaders(t *testing.T) {
+	L := lua.NewState()
+	defer L.Close()
+
+	result := L.NewTable()
+	L.SetField(result, "body", lua.LString("hello"))
+	L.SetField(result, "headers", lua.LString("text/plain"))
+
+	err := L.CallByParam(lua.P{
+		Fn: L.NewFunction(func(L *lua.LState) int {
+			UnpackRouteResult(L, "1", []lua.LValue{result, lua.LNil})
+			return 0
+		}),
+		Protect: true,
+	})
+	if err == nil {
+		t.Fatalf("expected an error but got nil")
+	}
+}
@@ -145,6 +145,15 @@ func StartTestServer() *httptest.Server {
 		})
 		fmt.Fprint(w, "ok")
 	})
+	mux.HandleFunc("/api/login", func(w http.ResponseWriter, r *http.Request) {
+		http.SetCookie(w, &http.Cookie{
+			Name:     "cookie_test",
+			Value:    "logged in",
+			Path:     "/",
+			HttpOnly: true,
+		})
+		fmt.Fprint(w, "ok")
+	})
 	mux.HandleFunc("/cookie/get", func(w http.ResponseWriter, r *http.Request) {
 		c, err := r.Cookie("cookie_test")
 		if err != nil {
@@ -163,6 +172,38 @@ func StartTestServer() *httptest.Server {
 		fmt.Fprint(w, "ok")
 	})
 
+	mux.HandleFunc("/navigation", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("content-type", "text/html")
+		fmt.Fprintf(w, `
+			<a id=link href="/?target=next">next</a>
+			<a id=hash href="#hash">hash</a>
+			<button id=delayed onclick="setTimeout(() => location.href = '/?target=delayed', 300)">delayed</button>
+			<span id=fetched></span>
+			<script>
+				window.addEventListener('load', () => {
+					setTimeout(() => fetch('/slow').then(r => r.text()).then(t => document.querySelector('#fetched').innerText = t), 100);
+				});
+			</script>
+		`)
+	})
+
+	mux.HandleFunc("/frames", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("content-type", "text/html")
+		fmt.Fprintf(w, `
+			<h1>frames</h1>
+			<iframe name="content" src="/?target=inner"></iframe>
+			<iframe id="second" src="/?target=second"></iframe>
+		`)
+	})
+	mux.HandleFunc("/frames/cross-origin", func(w http.ResponseWriter, r *http.Request) {
+		// The test server listens on 127.0.0.1, so "localhost" is another origin.
+		w.Header().Set("content-type", "text/html")
+		fmt.Fprintf(w, `
+			<h1>cross-origin frame</h1>
+			<iframe name="cross" src="http://%s/?target=cross"></iframe>
+		`, strings.Replace(r.Host, "127.0.0.1", "localhost", 1))
+	})
+
 	return httptest.NewServer(mux)
 }
 
@@ -264,3 +305,34 @@ stack traceback:
 		t.Fatalf("unexpected error:\n%s", err)
 	}
 }
+
+func Test_failOnError(t *testing.T) {
+	t.Parallel()
+
+	server := StartTestServer()
+	t.Cleanup(server.Close)
+
+	ctx, cancel := NewContext(Arg{Mode: "ayd", Timeout: 5 * time.Minute}, nil)
+	t.Cleanup(cancel)
+
+	target, _ := ayd.ParseURL("web-scenario:testdata/fail-on-error.lua")
+
+	s, err := NewStorage(t.TempDir(), time.Now())
+	if err != nil {
+		t.Fatalf("failed to prepare storage: %s", err)
+	}
+
+	logger := &Logger{Stream: (*DebugWriter)(t)}
+	env := NewEnvironment(ctx, logger, s, Arg{Mode: "ayd", Target: target})
+	defer env.Close()
+
+	RegisterTestUtil(env.lua, s, server)
+
+	expect := `uncaught exception in tab#1: Error: boom`
+
+	if err := env.DoFile("testdata/fail-on-error.lua"); err == nil {
+		t.Fatalf("expected error but got nil")
+	} else if err.Error() != expect {
+		t.Fatalf("unexpected error:\n%s", err)
+	}
+}
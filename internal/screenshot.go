@@ -0,0 +1,161 @@
+package webscenario
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"math"
+
+	"github.com/chromedp/cdproto/cdp"
+	"github.com/chromedp/cdproto/page"
+	"github.com/chromedp/chromedp"
+	"github.com/mailru/easyjson"
+	"github.com/mailru/easyjson/jwriter"
+	"github.com/yuin/gopher-lua"
+)
+
+type ScreenshotOptions struct {
+	Name     string
+	FullPage bool
+	Clip     *page.Viewport
+	Format   page.CaptureScreenshotFormat
+	Quality  *int64
+}
+
+// CheckScreenshotOptions parses the argument of tab:screenshot.
+// The argument can be nil, a file name string or number, or an option table.
+func CheckScreenshotOptions(L *lua.LState, n int) ScreenshotOptions {
+	opts := ScreenshotOptions{
+		Format: page.CaptureScreenshotFormatPng,
+	}
+
+	switch v := L.Get(n).(type) {
+	case *lua.LNilType:
+	case lua.LString, lua.LNumber:
+		opts.Name = L.ToString(n)
+	case *lua.LTable:
+		if name, ok := L.GetField(v, "name").(lua.LString); ok {
+			opts.Name = string(name)
+		}
+
+		opts.FullPage = lua.LVAsBool(L.GetField(v, "fullpage"))
+
+		switch clip := L.GetField(v, "clip").(type) {
+		case *lua.LNilType:
+		case *lua.LTable:
+			var xs [4]float64
+			for i, k := range []string{"x", "y", "w", "h"} {
+				x, ok := L.GetField(clip, k).(lua.LNumber)
+				if !ok {
+					L.ArgError(n, "clip field expected a table with x, y, w, and h.")
+				}
+				xs[i] = float64(x)
+			}
+			opts.Clip = &page.Viewport{X: xs[0], Y: xs[1], Width: xs[2], Height: xs[3], Scale: 1}
+		default:
+			L.ArgError(n, "clip field expected a table with x, y, w, and h.")
+		}
+
+		switch f := L.GetField(v, "format").(type) {
+		case *lua.LNilType:
+		case lua.LString:
+			switch page.CaptureScreenshotFormat(f) {
+			case page.CaptureScreenshotFormatPng, page.CaptureScreenshotFormatJpeg, page.CaptureScreenshotFormatWebp:
+				opts.Format = page.CaptureScreenshotFormat(f)
+			default:
+				L.ArgError(n, fmt.Sprintf("unsupported format %q. format should be \"png\", \"jpeg\", or \"webp\".", f))
+			}
+		default:
+			L.ArgError(n, "format field expected a string.")
+		}
+
+		switch q := L.GetField(v, "quality").(type) {
+		case *lua.LNilType:
+		case lua.LNumber:
+			if q < 0 || q > 100 {
+				L.ArgError(n, "quality field expected a number between 0 and 100.")
+			}
+			quality := int64(q)
+			opts.Quality = &quality
+		default:
+			L.ArgError(n, "quality field expected a number.")
+		}
+	default:
+		L.ArgError(n, "a nil, a string, or a table expected.")
+	}
+
+	return opts
+}
+
+// Ext returns file extension for the image format.
+func (opts ScreenshotOptions) Ext() string {
+	if opts.Format == page.CaptureScreenshotFormatJpeg {
+		return ".jpg"
+	}
+	return "." + string(opts.Format)
+}
+
+// Capture makes an action to take a screenshot.
+func (opts ScreenshotOptions) Capture(buf *[]byte) chromedp.Action {
+	return chromedp.ActionFunc(func(ctx context.Context) error {
+		capture := page.CaptureScreenshot().
+			WithFormat(opts.Format).
+			WithFromSurface(true)
+
+		clip := opts.Clip
+		if opts.FullPage && clip == nil {
+			_, _, _, _, _, size, err := page.GetLayoutMetrics().Do(ctx)
+			if err != nil {
+				return err
+			}
+			clip = &page.Viewport{
+				Width:  math.Ceil(size.Width),
+				Height: math.Ceil(size.Height),
+				Scale:  1,
+			}
+		}
+		if clip != nil {
+			capture = capture.WithClip(clip)
+		}
+		if opts.FullPage || clip != nil {
+			capture = capture.WithCaptureBeyondViewport(true)
+		}
+
+		if opts.Format == page.CaptureScreenshotFormatPng || opts.Quality == nil {
+			var err error
+			*buf, err = capture.Do(ctx)
+			return err
+		}
+
+		var res page.CaptureScreenshotReturns
+		if err := cdp.Execute(ctx, page.CommandCaptureScreenshot, captureParams{capture, *opts.Quality}, &res); err != nil {
+			return err
+		}
+		var err error
+		*buf, err = base64.StdEncoding.DecodeString(res.Data)
+		return err
+	})
+}
+
+// captureParams is a parameter for Page.captureScreenshot that always has the quality.
+// It is needed to request quality 0, because page.CaptureScreenshotParams omits the quality if it is 0.
+type captureParams struct {
+	*page.CaptureScreenshotParams
+	quality int64
+}
+
+func (p captureParams) MarshalEasyJSON(w *jwriter.Writer) {
+	var fields map[string]json.RawMessage
+	buf, err := easyjson.Marshal(p.CaptureScreenshotParams)
+	if err == nil {
+		err = json.Unmarshal(buf, &fields)
+	}
+	if err == nil {
+		fields["quality"], err = json.Marshal(p.quality)
+	}
+	if err == nil {
+		buf, err = json.Marshal(fields)
+	}
+	w.Raw(buf, err)
+}
@@ -0,0 +1,27 @@
+package webscenario
+
+import (
+	"testing"
+
+	"github.com/chromedp/cdproto/page"
+	"github.com/mailru/easyjson"
+)
+
+func TestCaptureParams(t *testing.T) {
+	tests := []struct {
+		quality int64
+		want    string
+	}{
+		{0, `{"format":"jpeg","quality":0}`},
+		{80, `{"format":"jpeg","quality":80}`},
+	}
+
+	for _, tt := range tests {
+		buf, err := easyjson.Marshal(captureParams{page.CaptureScreenshot().WithFormat(page.CaptureScreenshotFormatJpeg), tt.quality})
+		if err != nil {
+			t.Errorf("%d: failed to marshal: %s", tt.quality, err)
+		} else if string(buf) != tt.want {
+			t.Errorf("%d: unexpected json: %s", tt.quality, buf)
+		}
+	}
+}
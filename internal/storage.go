@@ -93,6 +93,38 @@ func (s *Storage) Save(name, ext string, data []byte) error {
 	return os.WriteFile(p, data, 0644)
 }
 
+func isFile(path string) bool {
+	st, err := os.Stat(path)
+	return err == nil && !st.IsDir()
+}
+
+// Lookup finds a file to read.
+// It searches the artifact directory of this execution first, the artifact directories of previous executions in newest first order next, and then the filesystem.
+// The returned path is always absolute.
+func (s *Storage) Lookup(name string) (string, bool) {
+	if !filepath.IsAbs(name) {
+		if p := filepath.Join(s.Dir, name); isFile(p) {
+			return p, true
+		}
+
+		base := filepath.Dir(s.Dir)
+		entries, _ := os.ReadDir(base)
+		for i := len(entries) - 1; i >= 0; i-- {
+			if entries[i].IsDir() {
+				if p := filepath.Join(base, entries[i].Name(), name); isFile(p) {
+					return p, true
+				}
+			}
+		}
+	}
+
+	p, err := filepath.Abs(name)
+	if err != nil || !isFile(p) {
+		return "", false
+	}
+	return p, true
+}
+
 func (s *Storage) StartDownload(guid, name string) {
 	s.Lock()
 	defer s.Unlock()
@@ -1,6 +1,7 @@
 package webscenario
 
 import (
+	"os"
 	"path/filepath"
 	"testing"
 	"time"
@@ -18,3 +19,80 @@ func TestNewStorage(t *testing.T) {
 		t.Errorf("unexpected storage directory: %s", s.Dir)
 	}
 }
+
+func TestStorage_Lookup(t *testing.T) {
+	t.Parallel()
+
+	tmpdir := t.TempDir()
+
+	s1, _ := NewStorage(tmpdir, time.Date(2022, 1, 1, 0, 0, 0, 0, time.UTC))
+	s2, _ := NewStorage(tmpdir, time.Date(2022, 1, 2, 0, 0, 0, 0, time.UTC))
+	s3, _ := NewStorage(tmpdir, time.Date(2022, 1, 3, 0, 0, 0, 0, time.UTC))
+
+	if _, ok := s3.Lookup("hello.txt"); ok {
+		t.Errorf("expected not found but found")
+	}
+
+	if err := s1.Save("hello", ".txt", []byte("1")); err != nil {
+		t.Fatalf("failed to save artifact: %s", err)
+	}
+	if p, ok := s3.Lookup("hello.txt"); !ok {
+		t.Errorf("expected found but not found")
+	} else if p != filepath.Join(s1.Dir, "hello.txt") {
+		t.Errorf("unexpected path: %s", p)
+	}
+
+	if err := s2.Save("hello", ".txt", []byte("2")); err != nil {
+		t.Fatalf("failed to save artifact: %s", err)
+	}
+	if p, ok := s3.Lookup("hello.txt"); !ok {
+		t.Errorf("expected found but not found")
+	} else if p != filepath.Join(s2.Dir, "hello.txt") {
+		t.Errorf("unexpected path: %s", p)
+	}
+
+	if err := s3.Save("hello", ".txt", []byte("3")); err != nil {
+		t.Fatalf("failed to save artifact: %s", err)
+	}
+	if p, ok := s3.Lookup("hello.txt"); !ok {
+		t.Errorf("expected found but not found")
+	} else if p != filepath.Join(s3.Dir, "hello.txt") {
+		t.Errorf("unexpected path: %s", p)
+	}
+}
+
+func TestStorage_Lookup_filesystem(t *testing.T) {
+	t.Parallel()
+
+	tmpdir := t.TempDir()
+	s, _ := NewStorage(tmpdir, time.Date(2022, 1, 1, 0, 0, 0, 0, time.UTC))
+
+	outside := filepath.Join(tmpdir, "outside.txt")
+	if err := os.WriteFile(outside, []byte("hello"), 0644); err != nil {
+		t.Fatalf("failed to write file: %s", err)
+	}
+	if err := s.Save("artifact", ".txt", []byte("world")); err != nil {
+		t.Fatalf("failed to save artifact: %s", err)
+	}
+
+	tests := []struct {
+		Name   string
+		Path   string
+		Exists bool
+	}{
+		{"artifact.txt", filepath.Join(s.Dir, "artifact.txt"), true},
+		{outside, outside, true},
+		{"missing.txt", "", false},
+		{filepath.Join(tmpdir, "missing.txt"), "", false},
+		{".", "", false},
+	}
+
+	for _, tt := range tests {
+		p, ok := s.Lookup(tt.Name)
+		if ok != tt.Exists {
+			t.Errorf("%s: expected exists=%v but got %v", tt.Name, tt.Exists, ok)
+		} else if p != tt.Path {
+			t.Errorf("%s: unexpected path: %s", tt.Name, p)
+		}
+	}
+}
@@ -11,8 +11,11 @@ import (
 
 	"github.com/chromedp/cdproto"
 	"github.com/chromedp/cdproto/browser"
+	"github.com/chromedp/cdproto/cdp"
+	"github.com/chromedp/cdproto/fetch"
 	"github.com/chromedp/cdproto/network"
 	"github.com/chromedp/cdproto/page"
+	"github.com/chromedp/cdproto/runtime"
 	"github.com/chromedp/chromedp"
 	"github.com/chromedp/chromedp/device"
 	"github.com/yuin/gopher-lua"
@@ -22,11 +25,113 @@ type LoadWaiter struct {
 	sync.Mutex
 
 	waits map[network.RequestID]chan struct{}
+
+	navigations int
+	waited      int
+	states      map[string]bool
+	changed     chan struct{}
 }
 
 func NewLoadWaiter() *LoadWaiter {
 	return &LoadWaiter{
 		waits: make(map[network.RequestID]chan struct{}),
+		// The initial blank page is treated as already loaded.
+		states: map[string]bool{
+			"DOMContentLoaded": true,
+			"load":             true,
+			"networkIdle":      true,
+		},
+		changed: make(chan struct{}),
+	}
+}
+
+// LoadStates is a map from the state names for tab:waitLoad() to the names of page lifecycle events.
+var LoadStates = map[string]string{
+	"domcontentloaded": "DOMContentLoaded",
+	"load":             "load",
+	"networkidle":      "networkIdle",
+}
+
+func (l *LoadWaiter) notify() {
+	close(l.changed)
+	l.changed = make(chan struct{})
+}
+
+// HandleLifecycle updates loading state by a lifecycle event of the main frame.
+func (l *LoadWaiter) HandleLifecycle(name string) {
+	l.Lock()
+	defer l.Unlock()
+
+	if name == "init" {
+		l.navigations++
+		l.states = make(map[string]bool)
+	} else {
+		l.states[name] = true
+	}
+	l.notify()
+}
+
+// NavigatedWithinDocument counts a navigation that doesn't load new document, such as changing fragment or history.pushState().
+func (l *LoadWaiter) NavigatedWithinDocument() {
+	l.Lock()
+	defer l.Unlock()
+
+	l.navigations++
+	l.notify()
+}
+
+// Navigations returns the number of navigations that happened so far.
+func (l *LoadWaiter) Navigations() int {
+	l.Lock()
+	defer l.Unlock()
+	return l.navigations
+}
+
+// Consume marks all navigations so far as waited.
+func (l *LoadWaiter) Consume() {
+	l.Lock()
+	defer l.Unlock()
+	l.waited = l.navigations
+}
+
+// WaitState waits until the current document reaches the lifecycle state.
+func (l *LoadWaiter) WaitState(ctx context.Context, state string) error {
+	return l.waitUntil(ctx, func() bool {
+		return l.states[state]
+	})
+}
+
+// WaitNavigation waits for a navigation after the specified number of navigations, and for the document loaded.
+// If after is negative, it waits for a navigation that is not waited yet.
+func (l *LoadWaiter) WaitNavigation(ctx context.Context, after int) error {
+	err := l.waitUntil(ctx, func() bool {
+		if after < 0 {
+			after = l.waited
+		}
+		return l.navigations > after && l.states["load"]
+	})
+	if err == nil {
+		l.Consume()
+	}
+	return err
+}
+
+func (l *LoadWaiter) waitUntil(ctx context.Context, cond func() bool) error {
+	for {
+		l.Lock()
+		ok := cond()
+		ch := l.changed
+		l.Unlock()
+
+		if ok {
+			return nil
+		}
+
+		select {
+		case <-ch:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
 	}
 }
 
@@ -54,22 +159,36 @@ type Tab struct {
 	env    *Environment
 
 	loading *LoadWaiter
+	mouse   *Mouse
 
 	id            int
 	width, height int64
+	timeout       time.Duration
 	dialogEvent   *EventHandler
 	downloadEvent *EventHandler
 	requestEvent  *EventHandler
 	responseEvent *EventHandler
+	consoleEvent  *EventHandler
+	errorEvent    *EventHandler
+	failOnError   bool
+	router        *Router
+	har           *HARRecorder
+	metricsKey    string
 
 	recorder *Recorder
 }
 
 func NewTab(ctx context.Context, L *lua.LState, env *Environment, id int) *Tab {
 	url := ""
-	width, height := int64(800), int64(800)
-	userAgent := ""
+	info := device.Info{Width: 800, Height: 800, Scale: 1}
 	recording := false
+	failOnError := false
+	harBody := false
+	metricsKey := ""
+	timeout := env.stepTimeout
+	var cookies []*network.CookieParam
+	var netcond *network.EmulateNetworkConditionsParams
+	var emulations []chromedp.Action
 
 	switch v := L.Get(1).(type) {
 	case lua.LString:
@@ -78,16 +197,72 @@ func NewTab(ctx context.Context, L *lua.LState, env *Environment, id int) *Tab {
 		if u, ok := L.GetField(v, "url").(lua.LString); ok {
 			url = string(u)
 		}
+		switch d := L.GetField(v, "device").(type) {
+		case *lua.LNilType:
+		case lua.LString:
+			var ok bool
+			if info, ok = LookupDevice(string(d)); !ok {
+				L.ArgError(1, fmt.Sprintf("unknown device %q. please see tab.devices for available devices.", d))
+			}
+		default:
+			L.ArgError(1, "device field expected be a string.")
+		}
 		if w, ok := L.GetField(v, "width").(lua.LNumber); ok {
-			width = int64(w)
+			info.Width = int64(w)
 		}
 		if h, ok := L.GetField(v, "height").(lua.LNumber); ok {
-			height = int64(h)
+			info.Height = int64(h)
 		}
 		if ua, ok := L.GetField(v, "useragent").(lua.LString); ok {
-			userAgent = string(ua)
+			info.UserAgent = string(ua)
+		}
+		switch x := L.GetField(v, "timeout").(type) {
+		case *lua.LNilType:
+		case lua.LNumber:
+			if x <= 0 {
+				L.ArgError(1, "timeout field expected a positive number.")
+			}
+			timeout = time.Duration(float64(x) * float64(time.Millisecond))
+		default:
+			L.ArgError(1, "timeout field expected a number.")
 		}
 		recording = lua.LVAsBool(L.GetField(v, "recording"))
+		failOnError = lua.LVAsBool(L.GetField(v, "failonerror"))
+		switch m := L.GetField(v, "metrics").(type) {
+		case *lua.LNilType:
+		case lua.LBool:
+			if m {
+				metricsKey = "metrics"
+			}
+		case lua.LString:
+			metricsKey = string(m)
+		default:
+			L.ArgError(1, "metrics field expected a boolean or a string.")
+		}
+		switch h := L.GetField(v, "har").(type) {
+		case *lua.LNilType:
+		case *lua.LTable:
+			harBody = lua.LVAsBool(L.GetField(h, "body"))
+		default:
+			L.ArgError(1, "har field expected a table.")
+		}
+		netcond = CheckNetworkConditions(L, 1, L.GetField(v, "network"))
+		for name, f := range Emulations {
+			if x := L.GetField(v, name); x.Type() != lua.LTNil {
+				emulations = append(emulations, f(L, 1, x))
+			}
+		}
+		switch j := L.GetField(v, "cookiejar").(type) {
+		case *lua.LNilType:
+		case *lua.LUserData:
+			if j, ok := j.Value.(*CookieJar); ok {
+				cookies = j.CookieParams()
+				break
+			}
+			L.ArgError(1, "cookiejar field expected cookiejar value.")
+		default:
+			L.ArgError(1, "cookiejar field expected cookiejar value.")
+		}
 	case *lua.LNilType:
 	default:
 		L.ArgError(1, "a nil, a string, or a table expected.")
@@ -100,39 +275,75 @@ func NewTab(ctx context.Context, L *lua.LState, env *Environment, id int) *Tab {
 			cancel:  cancel,
 			env:     env,
 			loading: NewLoadWaiter(),
+			mouse:   &Mouse{},
 
-			id:     id,
-			width:  width,
-			height: height,
+			id:      id,
+			width:   info.Width,
+			height:  info.Height,
+			timeout: timeout,
 
 			dialogEvent:   NewEventHandler((*Tab).HandleDialog),
 			downloadEvent: NewEventHandler((*Tab).HandleEvent),
 			requestEvent:  NewEventHandler((*Tab).HandleEvent),
 			responseEvent: NewEventHandler((*Tab).HandleEvent),
+			consoleEvent:  NewEventHandler((*Tab).HandleEvent),
+			errorEvent:    NewEventHandler((*Tab).HandleEvent),
+			failOnError:   failOnError,
+			router:        NewRouter(),
+			har:           NewHARRecorder(harBody),
+			metricsKey:    metricsKey,
 		}
-		err := t.RunInCallback(
+		actions := []chromedp.Action{
 			browser.SetDownloadBehavior(browser.SetDownloadBehaviorBehaviorAllow).WithDownloadPath(env.storage.Dir).WithEventsEnabled(true),
-			chromedp.Emulate(device.Info{
-				UserAgent: userAgent,
-				Width:     t.width,
-				Height:    t.height,
-				Scale:     1,
-			}),
-		)
+			chromedp.Emulate(info),
+			// Network events are always enabled to record HAR.
+			network.Enable(),
+		}
+		if len(cookies) > 0 {
+			actions = append(actions, network.SetCookies(cookies))
+		}
+		if netcond != nil {
+			actions = append(actions, netcond)
+		}
+		actions = append(actions, emulations...)
+		err := t.RunInCallback(actions...)
+		if err == nil {
+			t.listenLifecycle()
+		}
 		return t, err
 	})
 
 	if recording || env.EnableRecording {
-		t.recorder = NewRecorder(t.ctx, int(width), int(height))
+		t.recorder = NewRecorder(t.ctx, int(t.width), int(t.height))
 	}
 
 	if url != "" {
 		t.Run(L, fmt.Sprintf("$:go(%q)", url), true, 0, chromedp.Navigate(url))
+		t.loading.Consume()
 	}
 
 	return t
 }
 
+// listenLifecycle starts tracking navigations and loading state of the main frame.
+// It should be called before the first navigation, so it is separated from ToLua.
+func (t *Tab) listenLifecycle() {
+	mainFrame := cdp.FrameID(chromedp.FromContext(t.ctx).Target.TargetID)
+
+	chromedp.ListenTarget(t.ctx, func(ev any) {
+		switch e := ev.(type) {
+		case *page.EventLifecycleEvent:
+			if e.FrameID == mainFrame {
+				t.loading.HandleLifecycle(e.Name)
+			}
+		case *page.EventNavigatedWithinDocument:
+			if e.FrameID == mainFrame {
+				t.loading.NavigatedWithinDocument()
+			}
+		}
+	})
+}
+
 func CheckTab(L *lua.LState) *Tab {
 	if ud, ok := L.Get(1).(*lua.LUserData); ok {
 		if t, ok := ud.Value.(*Tab); ok {
@@ -144,12 +355,25 @@ func CheckTab(L *lua.LState) *Tab {
 	return nil
 }
 
+func checkTabArg(L *lua.LState, n int) *Tab {
+	if ud, ok := L.Get(n).(*lua.LUserData); ok {
+		if t, ok := ud.Value.(*Tab); ok {
+			return t
+		}
+	}
+
+	L.ArgError(n, "tab expected.")
+	return nil
+}
+
 func (t *Tab) ToLua(L *lua.LState) *lua.LUserData {
 	lt := L.NewUserData()
 	lt.Value = t
 	L.SetMetatable(lt, L.GetTypeMetatable("tab"))
 
 	chromedp.ListenTarget(t.ctx, func(ev any) {
+		t.har.Handle(ev)
+
 		switch e := ev.(type) {
 		case *page.EventJavascriptDialogOpening:
 			ev := t.env.BuildTable(func(L *lua.LState, ev *lua.LTable) {
@@ -195,6 +419,15 @@ func (t *Tab) ToLua(L *lua.LState) *lua.LUserData {
 				L.SetField(ev, "length", lua.LNumber(e.Response.EncodedDataLength))
 				L.SetField(ev, "remoteIP", lua.LString(e.Response.RemoteIPAddress))
 				L.SetField(ev, "remotePort", lua.LNumber(e.Response.RemotePort))
+				L.SetField(ev, "protocol", lua.LString(e.Response.Protocol))
+				L.SetField(ev, "fromCache", lua.LBool(e.Response.FromDiskCache || e.Response.FromPrefetchCache))
+				L.SetField(ev, "fromServiceWorker", lua.LBool(e.Response.FromServiceWorker))
+
+				L.SetField(ev, "timing", PackResourceTimings(L, CalcResourceTimings(e.Response.Timing, 0)))
+
+				if e.Response.SecurityDetails != nil {
+					L.SetField(ev, "securityDetails", PackSecurityDetails(L, e.Response.SecurityDetails))
+				}
 
 				L.SetMetatable(ev, AsFileLikeMeta(L, NewDelayedReader(func() io.Reader {
 					var body []byte
@@ -217,6 +450,21 @@ func (t *Tab) ToLua(L *lua.LState) *lua.LUserData {
 			})
 
 			t.responseEvent.Invoke(t, ev)
+		case *fetch.EventRequestPaused:
+			t.HandleRoute(e)
+		case *runtime.EventConsoleAPICalled:
+			ev := t.env.BuildTable(func(L *lua.LState, ev *lua.LTable) {
+				PackConsoleEvent(L, ev, e)
+			})
+			t.consoleEvent.Invoke(t, ev)
+		case *runtime.EventExceptionThrown:
+			ev := t.env.BuildTable(func(L *lua.LState, ev *lua.LTable) {
+				PackExceptionEvent(L, ev, e)
+			})
+			t.errorEvent.Invoke(t, ev)
+			if t.failOnError {
+				t.env.Abort(fmt.Errorf("uncaught exception in tab#%d: %s", t.id, ExceptionMessage(e.ExceptionDetails)))
+			}
 		}
 	})
 
@@ -284,12 +532,12 @@ func (t *Tab) RunSelector(L *lua.LState, taskName string, action ...chromedp.Act
 	t.env.StartTask(L.Where(1), taskName)
 
 	AsyncRun(t.env, L, func() (struct{}, error) {
-		ctx, cancel := context.WithTimeout(t.ctx, time.Second)
+		ctx, cancel := context.WithTimeout(t.ctx, t.timeout)
 		defer cancel()
 
 		err := chromedp.Run(ctx, action...)
 		if errors.Is(err, context.DeadlineExceeded) {
-			return struct{}{}, errors.New("no such element")
+			return struct{}{}, fmt.Errorf("no element matches %s within %s", taskName, t.timeout)
 		}
 		return struct{}{}, err
 	})
@@ -303,24 +551,32 @@ func (t *Tab) Go(L *lua.LState) {
 	url := L.CheckString(2)
 
 	t.Run(L, fmt.Sprintf("$:go(%q)", url), true, 0, chromedp.Navigate(url))
+	t.loading.Consume()
 }
 
 func (t *Tab) Forward(L *lua.LState) {
 	t.Run(L, "$:forward()", true, 0, chromedp.NavigateForward())
+	t.loading.Consume()
 }
 
 func (t *Tab) Back(L *lua.LState) {
 	t.Run(L, "$:back()", true, 0, chromedp.NavigateBack())
+	t.loading.Consume()
 }
 
 func (t *Tab) Reload(L *lua.LState) {
 	t.Run(L, "$:reload()", true, 0, chromedp.Reload())
+	t.loading.Consume()
 }
 
 func (t *Tab) Close() error {
 	AsyncRun(t.env, t.env.lua, func() (struct{}, error) {
 		t.wg.Wait()
 
+		if t.metricsKey != "" {
+			t.saveMetrics()
+		}
+
 		t.env.unregisterTab(t)
 
 		t.cancel()
@@ -333,12 +589,33 @@ func (t *Tab) Close() error {
 		t.downloadEvent.Close()
 		t.requestEvent.Close()
 		t.responseEvent.Close()
+		t.consoleEvent.Close()
+		t.errorEvent.Close()
 
 		return struct{}{}, nil
 	})
 	return nil
 }
 
+// saveMetrics records metrics of the tab into the extra values of the result.
+// It uses a new context because the tab's context is already cancelled if the scenario stopped by an error or timeout.
+func (t *Tab) saveMetrics() {
+	c := chromedp.FromContext(t.ctx)
+	if c == nil || c.Target == nil {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	m, err := CollectMetrics(cdp.WithExecutor(ctx, c.Target))
+	if err != nil {
+		t.env.logger.Print(lua.LString(fmt.Sprintf("failed to collect metrics: %s", err)))
+		return
+	}
+	t.env.logger.SetExtra(t.metricsKey, m)
+}
+
 func (t *Tab) LClose(L *lua.LState) {
 	if t.recorder != nil {
 		t.RecordOnce(L, "$:close()")
@@ -347,33 +624,136 @@ func (t *Tab) LClose(L *lua.LState) {
 }
 
 func (t *Tab) Screenshot(L *lua.LState) {
-	name := L.ToString(2)
+	opts := CheckScreenshotOptions(L, 2)
 
 	var buf []byte
 	t.Run(
 		L,
-		fmt.Sprintf("$:screenshot(%v)", name),
+		fmt.Sprintf("$:screenshot(%v)", opts.Name),
+		false,
+		0,
+		opts.Capture(&buf),
+		chromedp.ActionFunc(func(ctx context.Context) error {
+			return t.Save(opts.Name, opts.Ext(), buf)
+		}),
+	)
+}
+
+func (t *Tab) PDF(L *lua.LState) {
+	name := ""
+	n := 2
+	if s, ok := L.Get(2).(lua.LString); ok {
+		name = string(s)
+		n = 3
+	} else if L.Get(2).Type() == lua.LTNil {
+		n = 3
+	}
+	params := CheckPDFOptions(L, n)
+
+	t.Run(
+		L,
+		fmt.Sprintf("$:pdf(%v)", name),
+		false,
+		0,
+		chromedp.ActionFunc(func(ctx context.Context) error {
+			buf, _, err := params.Do(ctx)
+			if err != nil {
+				return err
+			}
+			return t.Save(name, ".pdf", buf)
+		}),
+	)
+}
+
+func (t *Tab) HAR(L *lua.LState) {
+	name := L.OptString(2, "")
+
+	t.Run(
+		L,
+		fmt.Sprintf("$:har(%v)", name),
 		false,
 		0,
-		chromedp.CaptureScreenshot(&buf),
 		chromedp.ActionFunc(func(ctx context.Context) error {
-			return t.Save(name, ".png", buf)
+			buf, err := t.har.Build(ctx)
+			if err != nil {
+				return err
+			}
+			return t.Save(name, ".har", buf)
 		}),
 	)
 }
 
+// waitLoading waits for loading state with the timeout in millisecond that is given as the n-th argument.
+func (t *Tab) waitLoading(L *lua.LState, taskName string, n int, wait func(ctx context.Context) error) {
+	timeout := time.Duration(float64(L.OptNumber(n, -1)) * float64(time.Millisecond))
+
+	t.env.StartTask(L.Where(1), taskName)
+
+	AsyncRun(t.env, L, func() (struct{}, error) {
+		ctx := t.ctx
+		if timeout >= 0 {
+			var cancel context.CancelFunc
+			ctx, cancel = context.WithTimeout(ctx, timeout)
+			defer cancel()
+		}
+		return struct{}{}, wait(ctx)
+	})
+
+	t.RecordOnce(L, taskName)
+}
+
+func (t *Tab) WaitNavigation(L *lua.LState) {
+	t.waitLoading(L, "$:waitNavigation()", 2, func(ctx context.Context) error {
+		return t.loading.WaitNavigation(ctx, -1)
+	})
+}
+
+func (t *Tab) WaitLoad(L *lua.LState) {
+	state := L.OptString(2, "load")
+	name, ok := LoadStates[state]
+	if !ok {
+		L.ArgError(2, fmt.Sprintf("unknown state %q. state should be \"domcontentloaded\", \"load\", or \"networkidle\".", state))
+	}
+
+	t.waitLoading(L, fmt.Sprintf("$:waitLoad(%q)", state), 3, func(ctx context.Context) error {
+		return t.loading.WaitState(ctx, name)
+	})
+}
+
 func (t *Tab) Wait(L *lua.LState) {
 	query := L.CheckString(2)
 	timeout := time.Duration(float64(L.OptNumber(3, 0)) * float64(time.Millisecond))
 
-	t.Run(L, fmt.Sprintf("$:wait(%q)", query), true, timeout, chromedp.WaitReady(query, chromedp.ByQuery))
+	t.Run(L, fmt.Sprintf("$:wait(%q)", query), true, timeout, chromedp.WaitReady(query, byQuery(query, false)))
+}
+
+func (t *Tab) WaitFunction(L *lua.LState) int {
+	script := L.CheckString(2)
+	timeout := time.Duration(float64(L.OptNumber(3, 0)) * float64(time.Millisecond))
+	interval := time.Duration(float64(L.OptNumber(4, 0)) * float64(time.Millisecond))
+
+	opts := []chromedp.PollOption{chromedp.WithPollingTimeout(timeout)}
+	if interval > 0 {
+		opts = append(opts, chromedp.WithPollingInterval(interval))
+	}
+
+	var res any
+	t.Run(L, fmt.Sprintf("$:waitFunction([[ %s ]])", script), true, timeout, chromedp.ActionFunc(func(ctx context.Context) error {
+		err := chromedp.Poll(script, &res, opts...).Do(ctx)
+		if errors.Is(err, chromedp.ErrPollingTimeout) {
+			return context.DeadlineExceeded
+		}
+		return err
+	}))
+	L.Push(PackLValue(L, res))
+	return 1
 }
 
 func (t *Tab) WaitVisible(L *lua.LState) {
 	query := L.CheckString(2)
 	timeout := time.Duration(float64(L.OptNumber(3, 0)) * float64(time.Millisecond))
 
-	t.Run(L, fmt.Sprintf("$:waitVisible(%q)", query), true, timeout, chromedp.WaitVisible(query, chromedp.ByQuery))
+	t.Run(L, fmt.Sprintf("$:waitVisible(%q)", query), true, timeout, chromedp.WaitVisible(query, byQuery(query, false)))
 }
 
 func (t *Tab) WaitXPath(L *lua.LState) {
@@ -390,6 +770,56 @@ func (t *Tab) WaitXPathVisible(L *lua.LState) {
 	t.Run(L, fmt.Sprintf("$:waitXPathVisible(%q)", query), true, timeout, chromedp.WaitVisible(query, chromedp.BySearch))
 }
 
+// waitHidden makes an action that waits until the element is not visible or not present.
+func waitHidden(query string, by chromedp.QueryOption) chromedp.ActionFunc {
+	return func(ctx context.Context) error {
+		ctx, cancel := context.WithCancel(ctx)
+		defer cancel()
+
+		errs := make(chan error, 2)
+		go func() {
+			errs <- chromedp.WaitNotVisible(query, by).Do(ctx)
+		}()
+		go func() {
+			errs <- chromedp.WaitNotPresent(query, by).Do(ctx)
+		}()
+
+		err := <-errs
+		if err != nil && ctx.Err() == nil {
+			err = <-errs
+		}
+		return err
+	}
+}
+
+func (t *Tab) WaitGone(L *lua.LState) {
+	query := L.CheckString(2)
+	timeout := time.Duration(float64(L.OptNumber(3, 0)) * float64(time.Millisecond))
+
+	t.Run(L, fmt.Sprintf("$:waitGone(%q)", query), true, timeout, chromedp.WaitNotPresent(query, byQuery(query, false)))
+}
+
+func (t *Tab) WaitHidden(L *lua.LState) {
+	query := L.CheckString(2)
+	timeout := time.Duration(float64(L.OptNumber(3, 0)) * float64(time.Millisecond))
+
+	t.Run(L, fmt.Sprintf("$:waitHidden(%q)", query), true, timeout, waitHidden(query, byQuery(query, false)))
+}
+
+func (t *Tab) WaitXPathGone(L *lua.LState) {
+	query := L.CheckString(2)
+	timeout := time.Duration(float64(L.OptNumber(3, 0)) * float64(time.Millisecond))
+
+	t.Run(L, fmt.Sprintf("$:waitXPathGone(%q)", query), true, timeout, chromedp.WaitNotPresent(query, chromedp.BySearch))
+}
+
+func (t *Tab) WaitXPathHidden(L *lua.LState) {
+	query := L.CheckString(2)
+	timeout := time.Duration(float64(L.OptNumber(3, 0)) * float64(time.Millisecond))
+
+	t.Run(L, fmt.Sprintf("$:waitXPathHidden(%q)", query), true, timeout, waitHidden(query, chromedp.BySearch))
+}
+
 func (t *Tab) WaitEvent(L *lua.LState, taskName string, h *EventHandler) int {
 	timeout := time.Duration(float64(L.OptNumber(2, -1)) * float64(time.Millisecond))
 
@@ -431,6 +861,14 @@ func (t *Tab) WaitResponse(L *lua.LState) int {
 	return t.WaitEvent(L, "t:waitResponse()", t.responseEvent)
 }
 
+func (t *Tab) WaitConsole(L *lua.LState) int {
+	return t.WaitEvent(L, "t:waitConsole()", t.consoleEvent)
+}
+
+func (t *Tab) WaitError(L *lua.LState) int {
+	return t.WaitEvent(L, "t:waitError()", t.errorEvent)
+}
+
 func (t *Tab) GetDialogs(L *lua.LState) int {
 	L.Push(t.dialogEvent.Status(L))
 	return 1
@@ -451,6 +889,16 @@ func (t *Tab) GetResponse(L *lua.LState) int {
 	return 1
 }
 
+func (t *Tab) GetConsoles(L *lua.LState) int {
+	L.Push(t.consoleEvent.Status(L))
+	return 1
+}
+
+func (t *Tab) GetErrors(L *lua.LState) int {
+	L.Push(t.errorEvent.Status(L))
+	return 1
+}
+
 func (t *Tab) HandleEvent(f *lua.LFunction, ev *lua.LTable) {
 	if f != nil {
 		t.wg.Add(1)
@@ -488,22 +936,130 @@ func (t *Tab) OnDownload(L *lua.LState) {
 	t.downloadEvent.SetFunc(L.OptFunction(2, nil))
 }
 
-func (t *Tab) updateNetworkConfig(L *lua.LState, taskName string) {
-	if t.requestEvent.IsFuncSet() || t.responseEvent.IsFuncSet() {
-		t.Run(L, taskName, false, 0, network.Enable())
-	} else {
-		t.Run(L, taskName, false, 0, network.Disable())
-	}
+func (t *Tab) OnConsole(L *lua.LState) {
+	t.consoleEvent.SetFunc(L.OptFunction(2, nil))
+}
+
+func (t *Tab) OnError(L *lua.LState) {
+	t.errorEvent.SetFunc(L.OptFunction(2, nil))
 }
 
 func (t *Tab) OnRequest(L *lua.LState) {
 	t.requestEvent.SetFunc(L.OptFunction(2, nil))
-	t.updateNetworkConfig(L, "$:onRequest()")
 }
 
 func (t *Tab) OnResponse(L *lua.LState) {
 	t.responseEvent.SetFunc(L.OptFunction(2, nil))
-	t.updateNetworkConfig(L, "$:onResponse()")
+}
+
+func (t *Tab) HandleRoute(e *fetch.EventRequestPaused) {
+	f := t.router.Find(e.Request.URL + e.Request.URLFragment)
+
+	var ev *lua.LTable
+	if f != nil {
+		ev = t.env.BuildTable(func(L *lua.LState, ev *lua.LTable) {
+			L.SetField(ev, "id", lua.LString(e.NetworkID.String()))
+			L.SetField(ev, "type", lua.LString(e.ResourceType.String()))
+			L.SetField(ev, "url", lua.LString(e.Request.URL+e.Request.URLFragment))
+			L.SetField(ev, "method", lua.LString(e.Request.Method))
+			L.SetField(ev, "headers", PackLValue(L, e.Request.Headers))
+			if e.Request.HasPostData {
+				L.SetField(ev, "body", lua.LString(e.Request.PostData))
+			}
+		})
+	}
+
+	t.wg.Add(1)
+	go func() {
+		if f == nil {
+			t.RunInCallback(fetch.ContinueRequest(e.RequestID))
+		} else {
+			// The result is unpacked in the same call as the handler, to report an invalid result as an error in the handler.
+			var action chromedp.Action = fetch.FailRequest(e.RequestID, network.ErrorReasonFailed)
+			t.env.CallEventHandler(t.env.NewFunction(func(L *lua.LState) int {
+				L.Push(f)
+				L.Push(L.Get(1))
+				L.Call(1, 2)
+				action = UnpackRouteResult(L, e.RequestID, []lua.LValue{L.Get(-2), L.Get(-1)})
+				return 0
+			}), ev, 0)
+
+			t.RunInCallback(action)
+		}
+		t.wg.Done()
+	}()
+}
+
+func (t *Tab) Route(L *lua.LState) {
+	pattern := L.CheckString(2)
+	t.router.Set(pattern, L.OptFunction(3, nil))
+
+	taskName := fmt.Sprintf("$:route(%q)", pattern)
+	if ps := t.router.Patterns(); len(ps) > 0 {
+		t.Run(L, taskName, false, 0, fetch.Enable().WithPatterns(ps))
+	} else {
+		t.Run(L, taskName, false, 0, fetch.Disable())
+	}
+}
+
+func (t *Tab) EmulateNetwork(L *lua.LState) {
+	netcond := CheckNetworkConditions(L, 2, L.Get(2))
+
+	var taskName string
+	if s, ok := L.Get(2).(lua.LString); ok {
+		taskName = fmt.Sprintf("$:emulateNetwork(%q)", s)
+	} else {
+		taskName = "$:emulateNetwork()"
+	}
+
+	if netcond != nil {
+		t.Run(L, taskName, false, 0, netcond)
+	} else {
+		t.Run(L, taskName, false, 0, network.EmulateNetworkConditions(false, 0, -1, -1))
+	}
+}
+
+func (t *Tab) emulate(L *lua.LState, taskName string, f EmulateFunc) {
+	t.Run(L, taskName, false, 0, f(L, 2, L.Get(2)))
+}
+
+func (t *Tab) EmulateCPU(L *lua.LState) {
+	t.emulate(L, "$:emulateCPU()", EmulateCPU)
+}
+
+func (t *Tab) EmulateTimezone(L *lua.LState) {
+	t.emulate(L, "$:emulateTimezone()", EmulateTimezone)
+}
+
+func (t *Tab) EmulateLocale(L *lua.LState) {
+	t.emulate(L, "$:emulateLocale()", EmulateLocale)
+}
+
+func (t *Tab) EmulateGeolocation(L *lua.LState) {
+	t.emulate(L, "$:emulateGeolocation()", EmulateGeolocation)
+}
+
+func (t *Tab) EmulateMedia(L *lua.LState) {
+	t.emulate(L, "$:emulateMedia()", EmulateMedia)
+}
+
+func (t *Tab) Scroll(L *lua.LState) {
+	x := L.CheckNumber(2)
+	y := L.CheckNumber(3)
+	t.Run(L, fmt.Sprintf("$:scroll(%v, %v)", x, y), true, 0, t.mouse.Wheel(float64(x), float64(y)))
+}
+
+func (t *Tab) Mouse(L *lua.LState) {
+	x := L.CheckNumber(2)
+	y := L.CheckNumber(3)
+	action := L.OptString(4, "move")
+
+	f, ok := MouseActions[action]
+	if !ok {
+		L.ArgError(4, fmt.Sprintf("unknown action %q. please use \"move\", \"down\", \"up\", or \"click\".", action))
+	}
+
+	t.Run(L, fmt.Sprintf("$:mouse(%v, %v, %q)", x, y, action), true, 0, f(t.mouse, float64(x), float64(y)))
 }
 
 func (t *Tab) Eval(L *lua.LState) int {
@@ -515,6 +1071,16 @@ func (t *Tab) Eval(L *lua.LState) int {
 	return 1
 }
 
+func (t *Tab) Metrics(L *lua.LState) int {
+	var metrics map[string]any
+	t.Run(L, "$:metrics()", false, 0, chromedp.ActionFunc(func(ctx context.Context) (err error) {
+		metrics, err = CollectMetrics(ctx)
+		return err
+	}))
+	L.Push(PackLValue(L, metrics))
+	return 1
+}
+
 func (t *Tab) GetURL(L *lua.LState) int {
 	var url string
 	t.Run(L, "$.url", false, 0, chromedp.Location(&url))
@@ -529,6 +1095,58 @@ func (t *Tab) GetTitle(L *lua.LState) int {
 	return 1
 }
 
+func (t *Tab) GetCookies(L *lua.LState) int {
+	var cookies []*network.Cookie
+	t.Run(L, "$.cookies", false, 0, chromedp.ActionFunc(func(ctx context.Context) (err error) {
+		cookies, err = network.GetCookies().Do(ctx)
+		return err
+	}))
+
+	tbl := L.NewTable()
+	for _, c := range cookies {
+		tbl.Append(PackCookie(L, CookieFromCDP(c)))
+	}
+	L.Push(tbl)
+	return 1
+}
+
+func (t *Tab) SetCookie(L *lua.LState) {
+	cookie, err := UnpackCookieParam(L, L.CheckTable(2))
+	if err != nil {
+		L.ArgError(2, err.Error())
+	}
+
+	t.Run(L, fmt.Sprintf("$:setCookie(%q)", cookie.Name), false, 0, chromedp.ActionFunc(func(ctx context.Context) error {
+		if cookie.URL == "" && cookie.Domain == "" {
+			if err := chromedp.Location(&cookie.URL).Do(ctx); err != nil {
+				return err
+			}
+		}
+		return network.SetCookies([]*network.CookieParam{cookie}).Do(ctx)
+	}))
+}
+
+func (t *Tab) ClearCookies(L *lua.LState) {
+	t.Run(L, "$:clearCookies()", false, 0, network.ClearBrowserCookies())
+}
+
+func (t *Tab) GetLocalStorage(L *lua.LState) int {
+	t.env.Yield()
+	L.Push(WebStorage{tab: t, local: true}.ToLua(L))
+	return 1
+}
+
+func (t *Tab) GetSessionStorage(L *lua.LState) int {
+	t.env.Yield()
+	L.Push(WebStorage{tab: t, local: false}.ToLua(L))
+	return 1
+}
+
+func (t *Tab) GetFrames(L *lua.LState) int {
+	L.Push(NewFramesTable(L, t))
+	return 1
+}
+
 func (t *Tab) GetViewport(L *lua.LState) int {
 	t.env.Yield()
 
@@ -555,24 +1173,53 @@ func RegisterTabType(ctx context.Context, env *Environment) {
 	}
 
 	methods := map[string]*lua.LFunction{
-		"go":               fn((*Tab).Go),
-		"forward":          fn((*Tab).Forward),
-		"back":             fn((*Tab).Back),
-		"reload":           fn((*Tab).Reload),
-		"close":            fn((*Tab).LClose),
-		"screenshot":       fn((*Tab).Screenshot),
-		"wait":             fn((*Tab).Wait),
-		"waitXPath":        fn((*Tab).WaitXPath),
-		"waitVisible":      fn((*Tab).WaitVisible),
-		"waitXPathVisible": fn((*Tab).WaitXPathVisible),
-		"waitDialog":       fret((*Tab).WaitDialog),
-		"waitDownload":     fret((*Tab).WaitDownload),
-		"waitRequest":      fret((*Tab).WaitRequest),
-		"waitResponse":     fret((*Tab).WaitResponse),
-		"onDialog":         fn((*Tab).OnDialog),
-		"onDownload":       fn((*Tab).OnDownload),
-		"onRequest":        fn((*Tab).OnRequest),
-		"onResponse":       fn((*Tab).OnResponse),
+		"go":                 fn((*Tab).Go),
+		"forward":            fn((*Tab).Forward),
+		"back":               fn((*Tab).Back),
+		"reload":             fn((*Tab).Reload),
+		"close":              fn((*Tab).LClose),
+		"screenshot":         fn((*Tab).Screenshot),
+		"pdf":                fn((*Tab).PDF),
+		"har":                fn((*Tab).HAR),
+		"metrics":            fret((*Tab).Metrics),
+		"wait":               fn((*Tab).Wait),
+		"waitXPath":          fn((*Tab).WaitXPath),
+		"waitVisible":        fn((*Tab).WaitVisible),
+		"waitXPathVisible":   fn((*Tab).WaitXPathVisible),
+		"waitGone":           fn((*Tab).WaitGone),
+		"waitHidden":         fn((*Tab).WaitHidden),
+		"waitXPathGone":      fn((*Tab).WaitXPathGone),
+		"waitXPathHidden":    fn((*Tab).WaitXPathHidden),
+		"waitFunction":       fret((*Tab).WaitFunction),
+		"getByText":          fret((*Tab).GetByText),
+		"getByRole":          fret((*Tab).GetByRole),
+		"getByLabel":         fret((*Tab).GetByLabel),
+		"getByTestId":        fret((*Tab).GetByTestID),
+		"waitNavigation":     fn((*Tab).WaitNavigation),
+		"waitLoad":           fn((*Tab).WaitLoad),
+		"waitDialog":         fret((*Tab).WaitDialog),
+		"waitDownload":       fret((*Tab).WaitDownload),
+		"waitRequest":        fret((*Tab).WaitRequest),
+		"waitResponse":       fret((*Tab).WaitResponse),
+		"waitConsole":        fret((*Tab).WaitConsole),
+		"waitError":          fret((*Tab).WaitError),
+		"onDialog":           fn((*Tab).OnDialog),
+		"onDownload":         fn((*Tab).OnDownload),
+		"onRequest":          fn((*Tab).OnRequest),
+		"onResponse":         fn((*Tab).OnResponse),
+		"onConsole":          fn((*Tab).OnConsole),
+		"onError":            fn((*Tab).OnError),
+		"scroll":             fn((*Tab).Scroll),
+		"mouse":              fn((*Tab).Mouse),
+		"route":              fn((*Tab).Route),
+		"setCookie":          fn((*Tab).SetCookie),
+		"clearCookies":       fn((*Tab).ClearCookies),
+		"emulateNetwork":     fn((*Tab).EmulateNetwork),
+		"emulateCPU":         fn((*Tab).EmulateCPU),
+		"emulateTimezone":    fn((*Tab).EmulateTimezone),
+		"emulateLocale":      fn((*Tab).EmulateLocale),
+		"emulateGeolocation": fn((*Tab).EmulateGeolocation),
+		"emulateMedia":       fn((*Tab).EmulateMedia),
 		"all": env.NewFunction(func(L *lua.LState) int {
 			t := CheckTab(L)
 			query := L.CheckString(2)
@@ -585,23 +1232,40 @@ func RegisterTabType(ctx context.Context, env *Environment) {
 			L.Push(NewElementsTableByXPath(L, t, query))
 			return 1
 		}),
+		"frame": env.NewFunction(func(L *lua.LState) int {
+			t := CheckTab(L)
+			query := L.CheckString(2)
+			L.Push(NewFrame(L, t, query).ToLua(L))
+			return 1
+		}),
 		"eval": env.NewFunction(func(L *lua.LState) int {
 			return CheckTab(L).Eval(L)
 		}),
 	}
 
 	getters := map[string]func(*Tab, *lua.LState) int{
-		"url":       (*Tab).GetURL,
-		"title":     (*Tab).GetTitle,
-		"viewport":  (*Tab).GetViewport,
-		"dialogs":   (*Tab).GetDialogs,
-		"downloads": (*Tab).GetDownload,
-		"requests":  (*Tab).GetRequest,
-		"responses": (*Tab).GetResponse,
+		"url":            (*Tab).GetURL,
+		"title":          (*Tab).GetTitle,
+		"viewport":       (*Tab).GetViewport,
+		"frames":         (*Tab).GetFrames,
+		"dialogs":        (*Tab).GetDialogs,
+		"downloads":      (*Tab).GetDownload,
+		"requests":       (*Tab).GetRequest,
+		"responses":      (*Tab).GetResponse,
+		"consoles":       (*Tab).GetConsoles,
+		"errors":         (*Tab).GetErrors,
+		"cookies":        (*Tab).GetCookies,
+		"localStorage":   (*Tab).GetLocalStorage,
+		"sessionStorage": (*Tab).GetSessionStorage,
 	}
 
 	count := 0
 
+	devices := env.lua.NewTable()
+	for _, d := range DeviceNames() {
+		devices.Append(lua.LString(d))
+	}
+
 	env.RegisterNewType("tab", map[string]lua.LGFunction{
 		"new": func(L *lua.LState) int {
 			count++
@@ -630,5 +1294,7 @@ func RegisterTabType(ctx context.Context, env *Environment) {
 			L.Push(lua.LString(fmt.Sprintf("tab#%d", CheckTab(L).id)))
 			return 1
 		},
-	}, nil)
+	}, map[string]lua.LValue{
+		"devices": devices,
+	})
 }
@@ -0,0 +1,7 @@
+t = tab.new({url=TEST.url(), failonerror=true})
+
+t:eval("setTimeout(() => { throw new Error('boom') }, 0)")
+
+time.sleep(1000)
+
+error("should not reach here")
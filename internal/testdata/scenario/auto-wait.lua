@@ -0,0 +1,70 @@
+t = tab.new(TEST.url("/"))
+
+t:eval([[
+    document.body.insertAdjacentHTML('beforeend', `
+        <button id="later" disabled onclick="this.innerText = 'clicked'">later</button>
+        <button id="hidden" style="display: none">hidden</button>
+        <button id="disabled" disabled>disabled</button>
+        <button id="moving" style="position: relative; animation: move 1s linear infinite">moving</button>
+        <style>@keyframes move { from { left: 0 } to { left: 100px } }</style>
+    `);
+
+    setTimeout(() => {
+        document.body.insertAdjacentHTML('beforeend', '<span id="appeared">appeared</span>');
+        document.querySelector('#later').disabled = false;
+    }, 300);
+]])
+
+assert.eq(t("#appeared").text, "appeared")
+
+t("#later"):click()
+assert.eq(t("#later").text, "clicked")
+
+t2 = tab.new({url=TEST.url("/"), timeout=200})
+
+t2:eval([[
+    document.body.insertAdjacentHTML('beforeend', `
+        <button id="hidden" style="display: none">hidden</button>
+        <button id="disabled" disabled>disabled</button>
+        <button id="moving" style="position: relative; animation: move 1s linear infinite">moving</button>
+        <style>@keyframes move { from { left: 0 } to { left: 100px } }</style>
+    `);
+    setTimeout(() => document.body.insertAdjacentHTML('beforeend', '<span id="too-late">too late</span>'), 1000);
+]])
+
+ok, err = pcall(t2, "#too-late")
+assert.eq(ok, false)
+assert.ne(err:find('no element matches $("#too-late") within 200ms', 1, true), nil)
+
+ok, err = pcall(t2("#hidden").click, t2("#hidden"))
+assert.eq(ok, false)
+assert.eq(err:match("element is not visible") ~= nil, true)
+
+ok, err = pcall(t2("#disabled").click, t2("#disabled"))
+assert.eq(ok, false)
+assert.eq(err:match("element is not enabled") ~= nil, true)
+
+ok, err = pcall(t2("#moving").click, t2("#moving"))
+assert.eq(ok, false)
+assert.ne(err:find("element is not stable within 200ms", 1, true), nil)
+
+t2:eval([[
+    document.body.insertAdjacentHTML('beforeend', `
+        <button id="empty" style="width: 0; height: 0; padding: 0; border: none"></button>
+        <button id="paused">paused</button>
+    `);
+]])
+
+ok, err = pcall(t2("#empty").click, t2("#empty"))
+assert.eq(ok, false)
+assert.eq(err:match("element is not visible") ~= nil, true)
+
+-- Animation frames never come, like pages in background.
+t2:eval("window.requestAnimationFrame = () => 0")
+t2("#paused"):hover()
+
+-- Reading properties doesn't wait.
+assert.eq(t2("#hidden").visible, false)
+assert.eq(t2("#disabled").enabled, false)
+
+assert.eq(pcall(tab.new, {timeout=-1}), false)
@@ -0,0 +1,46 @@
+t = tab.new(TEST.url())
+
+t:eval("console.log('hello', 'world', 42)")
+_, ev = t:waitConsole()
+assert.eq(ev, {
+    type      = "log",
+    message   = "hello world 42",
+    args      = {"hello", "world", 42},
+    timestamp = t.consoles[1].timestamp,
+    url       = t.consoles[1].url,
+    line      = t.consoles[1].line,
+    column    = t.consoles[1].column,
+})
+
+
+warnings = {}
+t:onConsole(function(ev)
+    if ev.type == "warning" then
+        table.insert(warnings, ev.message)
+    end
+end)
+t:eval("console.warn('be careful')")
+while #warnings == 0 do
+    time.sleep(10)
+end
+assert.eq(warnings, {"be careful"})
+t:onConsole(nil)
+
+
+t:eval("setTimeout(() => { throw new Error('boom') }, 0)")
+_, err = t:waitError()
+assert.eq(err.message, "Error: boom")
+assert.ne(err.stack:find("Error: boom", 1, true), nil)
+assert.eq(#t.errors, 1)
+assert.eq(t.errors._waited, 1)
+
+
+messages = {}
+t:onError(function(ev)
+    table.insert(messages, ev.message)
+end)
+t:eval("setTimeout(() => { throw new TypeError('oops') }, 0)")
+while #messages == 0 do
+    time.sleep(10)
+end
+assert.eq(messages, {"TypeError: oops"})
@@ -0,0 +1,90 @@
+t = tab.new(TEST.url("/cookie/get"))
+assert.eq(t.cookies, {})
+assert.eq(t("body").text, "not set")
+
+t:setCookie({name="cookie_test", value="from tab", path="/", httponly=true})
+assert.eq(t.cookies, {{
+    name     = "cookie_test",
+    value    = "from tab",
+    path     = "/",
+    domain   = "127.0.0.1",
+    secure   = false,
+    httponly = true,
+    samesite = "",
+}})
+
+t:reload()
+assert.eq(t("body").text, "from tab")
+assert.eq(t:eval("document.cookie"), "")
+
+t:clearCookies()
+assert.eq(t.cookies, {})
+t:reload()
+assert.eq(t("body").text, "not set")
+
+
+t2 = tab.new()
+t2:setCookie({name="cookie_test", value="before open", url=TEST.url("/")})
+t2:go(TEST.url("/cookie/get"))
+assert.eq(t2("body").text, "before open")
+t2:clearCookies()
+
+
+t:go(TEST.url("/"))
+assert.eq(t.localStorage.foo, nil)
+assert.eq(t.localStorage(), {})
+
+t.localStorage.foo = "bar"
+assert.eq(t.localStorage.foo, "bar")
+assert.eq(t:eval("localStorage.getItem('foo')"), "bar")
+assert.eq(t.localStorage(), {foo="bar"})
+
+t.localStorage.foo = nil
+assert.eq(t.localStorage.foo, nil)
+assert.eq(t.localStorage(), {})
+
+t.sessionStorage.hello = 123
+assert.eq(t.sessionStorage.hello, "123")
+assert.eq(t:eval("sessionStorage.getItem('hello')"), "123")
+assert.eq(t.localStorage(), {})
+
+t.sessionStorage.hello = nil
+assert.eq(t.sessionStorage(), {})
+
+
+_, jar = fetch(TEST.url("/cookie/set"))
+t3 = tab.new({url=TEST.url("/cookie/get"), cookiejar=jar})
+assert.eq(t3("body").text, "hello world")
+t3:clearCookies()
+
+t3:reload()
+assert.eq(t3("body").text, "not set")
+jar:export(t3)
+t3:reload()
+assert.eq(t3("body").text, "hello world")
+
+t3:clearCookies()
+t3:setCookie({name="cookie_test", value="from browser", path="/"})
+_, jar2 = fetch(TEST.url("/cookie/get"))
+resp = fetch(TEST.url("/cookie/get"), {cookiejar=jar2})
+assert.eq(resp:read("*all"), "not set")
+jar2:import(t3)
+resp = fetch(TEST.url("/cookie/get"), {cookiejar=jar2})
+assert.eq(resp:read("*all"), "from browser")
+t3:clearCookies()
+
+-- Cookies set for other hosts than the current page are imported as well.
+t3:go((TEST.url("/cookie/set"):gsub("127.0.0.1", "localhost")))
+t3:go(TEST.url("/"))
+_, jar3 = fetch(TEST.url("/"))
+jar3:import(t3)
+resp = fetch((TEST.url("/cookie/get"):gsub("127.0.0.1", "localhost")), {cookiejar=jar3})
+assert.eq(resp:read("*all"), "hello world")
+t3:clearCookies()
+
+-- Cookies keep their attributes, even if the page to set and the page to read are in different directories.
+_, jar4 = fetch(TEST.url("/api/login"))
+t4 = tab.new({url=TEST.url("/cookie/get"), cookiejar=jar4})
+assert.eq(t4("body").text, "logged in")
+assert.eq(t4:eval("document.cookie"), "")
+t4:clearCookies()
@@ -0,0 +1,39 @@
+t = tab.new({url=TEST.url("/"), width=800, height=600})
+
+t:eval([[
+    document.body.style.margin = '0';
+    document.body.insertAdjacentHTML('beforeend', `
+        <div id="banner" style="position: absolute; left: 10px; top: 20px; width: 100px; height: 30px">banner</div>
+        <button id="buy" style="color: rgb(255, 0, 0)">buy</button>
+        <button id="disabled" disabled>disabled</button>
+        <input id="check" type="checkbox" checked>
+        <input id="uncheck" type="checkbox">
+        <div id="hidden" style="display: none">hidden</div>
+        <div id="invisible" style="visibility: hidden">invisible</div>
+        <div id="empty"></div>
+    `);
+]])
+
+banner = t("#banner")
+assert.eq(banner.rect, {x=10, y=20, width=100, height=30})
+assert.eq(banner.rect.y + banner.rect.height <= t.viewport.height, true)
+assert.eq(banner:style("width"), "100px")
+
+buy = t("#buy")
+assert.eq(buy.visible, true)
+assert.eq(buy.enabled, true)
+assert.eq(buy.checked, false)
+assert.eq(buy.tagName, "BUTTON")
+assert.eq(buy:style("color"), "rgb(255, 0, 0)")
+
+assert.eq(t("#disabled").enabled, false)
+
+assert.eq(t("#check").checked, true)
+assert.eq(t("#uncheck").checked, false)
+assert.eq(t("#check").tagName, "INPUT")
+
+assert.eq(t("#hidden").visible, false)
+assert.eq(t("#hidden").rect, nil)
+assert.eq(t("#invisible").visible, false)
+assert.eq(t("#empty").visible, false)
+assert.eq(t("#empty").rect, {x=0, y=t("#empty").rect.y, width=800, height=0})
@@ -0,0 +1,27 @@
+t = tab.new({url=TEST.url("/"), device="iPhone 13"})
+
+assert.eq(t:eval("window.innerWidth"), 390)
+assert.eq(t:eval("window.devicePixelRatio"), 3)
+assert.eq(t:eval("navigator.maxTouchPoints > 0"), true)
+assert.eq(t:eval("navigator.userAgent.includes('iPhone')"), true)
+t:close()
+
+
+t = tab.new({url=TEST.url("/"), device="pixel 5", width=400})
+assert.eq(t:eval("window.innerWidth"), 400)
+assert.eq(t:eval("navigator.userAgent.includes('Pixel 5')"), true)
+t:close()
+
+
+ok, err = pcall(tab.new, {device="no such device"})
+assert.eq(ok, false)
+assert.ne(err:find('unknown device "no such device"', 1, true), nil)
+
+
+found = false
+for _, name in ipairs(tab.devices) do
+    if name == "iPhone 13" then
+        found = true
+    end
+end
+assert.eq(found, true)
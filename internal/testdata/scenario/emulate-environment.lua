@@ -0,0 +1,47 @@
+t = tab.new({
+    url      = TEST.url("/"),
+    timezone = "Asia/Tokyo",
+    locale   = "ja-JP",
+    media    = {colorScheme="dark"},
+    cpu      = 2,
+})
+
+assert.eq(t:eval("Intl.DateTimeFormat().resolvedOptions().timeZone"), "Asia/Tokyo")
+assert.eq(t:eval("new Date(0).getTimezoneOffset()"), -540)
+assert.eq(t:eval("Intl.DateTimeFormat().resolvedOptions().locale"), "ja-JP")
+assert.eq(t:eval("matchMedia('(prefers-color-scheme: dark)').matches"), true)
+
+
+t:emulateTimezone("America/New_York")
+assert.eq(t:eval("Intl.DateTimeFormat().resolvedOptions().timeZone"), "America/New_York")
+t:emulateTimezone(nil)
+assert.eq(t:eval("Intl.DateTimeFormat().resolvedOptions().timeZone"), "UTC")
+
+t:emulateLocale("fr-FR")
+assert.eq(t:eval("Intl.DateTimeFormat().resolvedOptions().locale"), "fr-FR")
+
+t:emulateMedia({colorScheme="light", reducedMotion="reduce"})
+assert.eq(t:eval("matchMedia('(prefers-color-scheme: dark)').matches"), false)
+assert.eq(t:eval("matchMedia('(prefers-reduced-motion: reduce)').matches"), true)
+t:emulateMedia(nil)
+assert.eq(t:eval("matchMedia('(prefers-reduced-motion: reduce)').matches"), false)
+
+t:emulateCPU(4)
+t:emulateCPU(nil)
+ok = pcall(t.emulateCPU, t, 0.5)
+assert.eq(ok, false)
+
+
+t:emulateGeolocation({latitude=35.5, longitude=139.5})
+t:eval([[
+    navigator.geolocation.getCurrentPosition(p => {
+        document.title = p.coords.latitude + "," + p.coords.longitude;
+    })
+]])
+for i = 1, 50 do
+    if t.title ~= "world - test" then
+        break
+    end
+    time.sleep(100*time.millisecond)
+end
+assert.eq(t.title, "35.5,139.5")
@@ -0,0 +1,27 @@
+t = tab.new({url=TEST.url("/"), network={latency=300}})
+
+before = time.now()
+t:reload()
+assert.ge(time.now() - before, 300*time.millisecond)
+
+
+t:emulateNetwork("offline")
+ok, err = pcall(t.go, t, TEST.url("/"))
+assert.eq(ok, false)
+assert.eq(err, "testdata/scenario/emulate-network.lua:9: page load error net::ERR_INTERNET_DISCONNECTED")
+
+
+t:emulateNetwork(nil)
+t:go(TEST.url("/?target=online"))
+assert.eq(t("b").text, "online")
+
+
+t:emulateNetwork("slow-4g")
+t:go(TEST.url("/?target=slow-4g"))
+assert.eq(t("b").text, "slow-4g")
+
+
+ok = pcall(t.emulateNetwork, t, "5g")
+assert.eq(ok, false)
+ok = pcall(t.emulateNetwork, t, {latency="fast"})
+assert.eq(ok, false)
@@ -0,0 +1,35 @@
+function find(entries, url)
+    for _, e in ipairs(entries) do
+        if e.request.url == url then
+            return e
+        end
+    end
+end
+
+
+t = tab.new({url=TEST.url("/"), har={body=true}})
+t:go(TEST.url("/?target=har"))
+
+t:har("session")
+assert.eq(artifact.list, {"session.har"})
+
+har = fromjson(artifact.open("session.har"):read("*a"))
+assert.eq(har.log.version, "1.2")
+
+e = find(har.log.entries, TEST.url("/?target=har"))
+assert.ne(e, nil)
+assert.eq(e.request.method, "GET")
+assert.eq(e.request.queryString, {{name="target", value="har"}})
+assert.eq(e.response.status, 200)
+assert.eq(e.response.content.text, [[<title>har - test</title><div id="greeting">hello <b class="target">har</b>!</div>]])
+assert.eq(e.time > 0, true)
+
+
+t2 = tab.new(TEST.url("/?target=nobody"))
+t2:har("nobody")
+
+har = fromjson(artifact.open("nobody.har"):read("*a"))
+e = find(har.log.entries, TEST.url("/?target=nobody"))
+assert.ne(e, nil)
+assert.eq(e.response.status, 200)
+assert.eq(e.response.content.text, nil)
@@ -11,20 +11,28 @@ t:onResponse(function(resp)
     assert.ne(resp.remoteIP, "")
 
     assert.eq(resp, {
-        id         = resp.id,
-        type       = "Document",
-        url        = TEST.url("/"),
-        status     = 200,
-        length     = 116,
-        remoteIP   = resp.remoteIP,
-        remotePort = resp.remotePort,
-        headers    = {
+        id                = resp.id,
+        type              = "Document",
+        url               = TEST.url("/"),
+        status            = 200,
+        length            = 116,
+        remoteIP          = resp.remoteIP,
+        remotePort        = resp.remotePort,
+        protocol          = "http/1.1",
+        fromCache         = false,
+        fromServiceWorker = false,
+        timing            = resp.timing,
+        headers           = {
             Date               = resp.headers.Date,
             ["Content-Type"]   = "text/html; charset=utf-8",
             ["Content-Length"] = "86",
         },
     })
 
+    assert.le(0, resp.timing.send)
+    assert.le(0, resp.timing.wait)
+    assert.eq(resp.securityDetails, nil)
+
     assert.eq(resp:read("all"), '<title>world - test</title><div id="greeting">hello <b class="target">world</b>!</div>')
 end)
 t:go(TEST.url("/"))
@@ -33,14 +41,18 @@ assert.eq(called, true)
 
 assert.eq(t.responses, {
     {
-        id         = t.responses[1].id,
-        type       = "Document",
-        url        = TEST.url("/"),
-        status     = 200,
-        length     = 116,
-        remoteIP   = "127.0.0.1",
-        remotePort = t.responses[1].remotePort,
-        headers    = {
+        id                = t.responses[1].id,
+        type              = "Document",
+        url               = TEST.url("/"),
+        status            = 200,
+        length            = 116,
+        remoteIP          = "127.0.0.1",
+        remotePort        = t.responses[1].remotePort,
+        protocol          = "http/1.1",
+        fromCache         = false,
+        fromServiceWorker = false,
+        timing            = t.responses[1].timing,
+        headers           = {
             Date               = t.responses[1].headers.Date,
             ["Content-Type"]   = "text/html; charset=utf-8",
             ["Content-Length"] = "86",
@@ -53,3 +65,8 @@ for _, r in ipairs(t.responses) do
     _, w = t:waitResponse(0)
     assert.eq(r, w)
 end
+
+
+timing = t.responses[1].timing
+assert.eq(timing.tls, -1)
+assert.eq(timing.receive, nil)
@@ -0,0 +1,42 @@
+t = tab.new(TEST.url("/frames"))
+t:waitLoad()
+
+f = t:frame("content")
+assert.eq(tostring(f), '$:frame("content")')
+assert.eq(f.name, "content")
+assert.eq(f.url, TEST.url("/?target=inner"))
+
+assert.eq(f("b.target").text, "inner")
+assert.eq(t:frame("#second")("b.target").text, "second")
+assert.eq(#f:all("b"), 1)
+assert.eq(#f:xpath("//b[@class='target']"), 1)
+assert.eq(f:eval("location.search"), "?target=inner")
+
+f:eval("setTimeout(() => document.body.insertAdjacentHTML('beforeend', '<p id=late>late</p>'), 100)")
+f:wait("#late", 1000)
+assert.eq(f("#late").text, "late")
+
+f:eval("location.search = '?target=moved'")
+t:waitFunction("document.querySelector('iframe[name=content]').contentDocument.querySelector('b.target')?.innerText === 'moved'", 1000)
+assert.eq(f("b.target").text, "moved")
+
+frames = t.frames
+assert.eq(#frames, 2)
+assert.eq(frames[1].name, "content")
+assert.eq(frames[2]("b.target").text, "second")
+
+assert.eq(pcall(t.frame, t, "no-such-frame"), false)
+assert.eq(pcall(t.frame, t, "h1"), false)
+
+t2 = tab.new(TEST.url("/frames/cross-origin"))
+t2:waitLoad()
+
+cross = t2:frame("cross")
+assert.eq(t2:eval("location.hostname"), "127.0.0.1")
+assert.eq(cross:eval("location.hostname"), "localhost")
+assert.eq(cross("b.target").text, "cross")
+assert.eq(#cross:all("b"), 1)
+
+cross:eval("document.body.insertAdjacentHTML('beforeend', '<input id=field>')")
+cross("#field"):sendKeys("hello")
+assert.eq(cross("#field").value, "hello")
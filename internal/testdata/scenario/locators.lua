@@ -0,0 +1,41 @@
+t = tab.new(TEST.url("/"))
+
+t:eval([[
+    document.body.insertAdjacentHTML('beforeend', `
+        <h2 class="x1f3a">Shopping cart</h2>
+        <p id="total">Total: <b id="price">1,200 yen</b></p>
+        <button id="buy" class="b9c2e">Buy now</button>
+        <button id="cancel" aria-label="Cancel order">&times;</button>
+        <input id="coupon" type="submit" value="Apply coupon">
+        <label for="email">E-mail</label><input id="email" type="email">
+        <label><input id="agree" type="checkbox"> I agree to the terms</label>
+        <span id="phone-label">Phone number</span><input id="phone" aria-labelledby="phone-label">
+        <input id="zip" aria-label="ZIP code">
+        <div data-testid="cart-item" id="item">item</div>
+        <script>const hidden = "Buy now";</script>
+    `);
+]])
+
+assert.eq(t:getByText("1,200 YEN").id, "price")
+assert.eq(t:getByText("Total: 1,200 yen", {exact=true}).id, "total")
+assert.eq(t:getByText("buy").id, "buy")
+assert.eq(t:getByText("apply coupon").id, "coupon")
+assert.eq(pcall(t.getByText, t, "buy", {exact=true}), false)
+assert.eq(tostring(t:getByText("buy")), '$:getByText("buy")')
+
+assert.eq(t:getByRole("button", {name="Buy now"}).id, "buy")
+assert.eq(t:getByRole("button", {name="Cancel order"}).id, "cancel")
+assert.eq(t:getByRole("heading").text, "Shopping cart")
+assert.eq(t:getByRole("checkbox").id, "agree")
+assert.eq(pcall(t.getByRole, t, "button", {name="Delete"}), false)
+
+assert.eq(t:getByLabel("E-mail").id, "email")
+assert.eq(t:getByLabel("i agree").id, "agree")
+assert.eq(t:getByLabel("Phone number", {exact=true}).id, "phone")
+assert.eq(t:getByLabel("zip").id, "zip")
+
+assert.eq(t:getByTestId("cart-item").id, "item")
+assert.eq(pcall(t.getByTestId, t, "cart"), false)
+
+t:getByLabel("E-mail"):sendKeys("alice@example.com")
+assert.eq(t("#email").value, "alice@example.com")
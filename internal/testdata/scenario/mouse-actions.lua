@@ -0,0 +1,53 @@
+t = tab.new({url=TEST.url("/"), width=800, height=600})
+
+t:eval([[
+    document.body.style.margin = '0';
+    document.body.insertAdjacentHTML('beforeend', `
+        <div id="menu" style="position: absolute; left: 0; top: 0; width: 100px; height: 50px">menu</div>
+        <div id="board" style="position: absolute; left: 200px; top: 0; width: 300px; height: 100px">
+            <div id="card" draggable="true" style="width: 50px; height: 20px">card</div>
+        </div>
+        <div id="todo" style="position: absolute; left: 200px; top: 200px; width: 300px; height: 100px">todo</div>
+        <div id="bottom" style="position: absolute; left: 0; top: 2000px; width: 100px; height: 50px">bottom</div>
+    `);
+
+    window.hovered = false;
+    document.querySelector('#menu').addEventListener('mouseenter', () => { window.hovered = true });
+
+    window.events = [];
+    document.addEventListener('mousedown', (ev) => events.push(['down', ev.clientX, ev.clientY]));
+    document.addEventListener('mouseup', (ev) => events.push(['up', ev.clientX, ev.clientY]));
+
+    const todo = document.querySelector('#todo');
+    todo.addEventListener('dragover', (ev) => ev.preventDefault());
+    todo.addEventListener('drop', (ev) => {
+        ev.preventDefault();
+        todo.appendChild(document.querySelector('#card'));
+    });
+]])
+
+t("#menu"):hover()
+assert.eq(t:eval("window.hovered"), true)
+
+t("#card"):dragTo(t("#todo"))
+assert.eq(t:eval("document.querySelector('#card').parentElement.id"), "todo")
+
+t:eval("window.events = []")
+t("#menu"):dragTo({x=50, y=200})
+assert.eq(t:eval("window.events"), {{"down", 50, 25}, {"up", 50, 200}})
+
+t:eval("window.events = []")
+t:mouse(10, 20, "down")
+t:mouse(30, 40)
+t:mouse(30, 40, "up")
+t:mouse(60, 70, "click")
+assert.eq(t:eval("window.events"), {{"down", 10, 20}, {"up", 30, 40}, {"down", 60, 70}, {"up", 60, 70}})
+
+t("#bottom"):scrollIntoView()
+assert.eq(t:eval("window.scrollY > 0"), true)
+
+t:eval("window.scrollTo(0, 0)")
+t:scroll(0, 300)
+t:waitFunction("window.scrollY === 300", 1000)
+
+assert.eq(pcall(t.mouse, t, 0, 0, "wheel"), false)
@@ -0,0 +1,21 @@
+t = tab.new(TEST.url("/"))
+
+m = t:metrics()
+
+assert.le(0, m.navigation.ttfb)
+assert.le(m.navigation.ttfb, m.navigation.domContentLoaded)
+assert.le(m.navigation.domContentLoaded, m.navigation.load)
+assert.le(0, m.fcp)
+assert.le(0, m.lcp)
+assert.eq(m.cls, 0)
+assert.eq(m.tbt, 0)
+assert.lt(0, m.counters.Nodes)
+assert.lt(0, m.counters.JSHeapUsedSize)
+assert.lt(0, m.counters.LayoutCount)
+
+
+t:eval([[
+    document.body.insertAdjacentHTML('afterbegin', '<div style="height: 300px">shift</div>');
+]])
+time.sleep(100)
+assert.lt(0, t:metrics().cls)
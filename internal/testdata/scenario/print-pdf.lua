@@ -0,0 +1,20 @@
+t = tab.new(TEST.url())
+
+t:pdf()
+assert.eq(artifact.list, {"000001.pdf"})
+assert.eq(artifact.open("000001.pdf", "rb"):read("*a"):sub(1, 5), "%PDF-")
+
+t:pdf("invoice", {paper="A4", landscape=true, margin="10mm", background=true})
+assert.eq(artifact.list, {"000001.pdf", "invoice.pdf"})
+
+t:pdf({paper={width=4, height="10cm"}, margin={top=0, bottom="1in"}})
+assert.eq(artifact.list, {"000001.pdf", "000002.pdf", "invoice.pdf"})
+
+
+ok, err = pcall(t.pdf, t, "x", {paper="B99"})
+assert.eq(ok, false)
+assert.ne(err:find('unknown paper size "B99"', 1, true), nil)
+
+ok, err = pcall(t.pdf, t, "x", {margin="1em"})
+assert.eq(ok, false)
+assert.ne(err:find('margin field expected', 1, true), nil)
@@ -14,7 +14,7 @@ assert.eq(t("#greeting").id, "greeting")
 
 ok, err = pcall(t, "#no-such-element")
 assert.eq(ok, false)
-assert.eq(err, "testdata/scenario/read-simple-html.lua:15: no such element")
+assert.eq(err, 'testdata/scenario/read-simple-html.lua:15: no element matches $("#no-such-element") within 5s')
 
 ok = pcall(t.wait, t, "#greeting", 100*time.millisecond)
 assert.eq(ok, true)
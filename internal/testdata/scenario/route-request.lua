@@ -0,0 +1,44 @@
+t = tab.new()
+
+
+t:route("*/error", function(req)
+    assert.eq(req, {
+        id      = req.id,
+        type    = "Document",
+        url     = TEST.url("/error"),
+        method  = "GET",
+        headers = req.headers,
+    })
+
+    return {
+        status  = 200,
+        headers = {["Content-Type"]="text/html"},
+        body    = "<span>mocked</span>",
+    }
+end)
+t:go(TEST.url("/error"))
+assert.eq(t("span").text, "mocked")
+
+
+called = false
+t:route("*/?target=*", function(req)
+    called = true
+end)
+t:go(TEST.url("/?target=route"))
+assert.eq(called, true)
+assert.eq(t("b").text, "route")
+
+
+t:route("*/slow", function(req)
+    return false
+end)
+ok, err = pcall(t.go, t, TEST.url("/slow"))
+assert.eq(ok, false)
+assert.eq(err, "testdata/scenario/route-request.lua:35: page load error net::ERR_BLOCKED_BY_CLIENT")
+
+
+t:route("*/error", nil)
+t:route("*/?target=*", nil)
+t:route("*/slow", nil)
+t:go(TEST.url("/error"))
+assert.eq(t("body").text, "something wrong!")
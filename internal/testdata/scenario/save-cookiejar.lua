@@ -0,0 +1,19 @@
+_, jar = fetch(TEST.url("/cookie/set"))
+jar:save("session")
+assert.eq(artifact.list, {"session.json"})
+
+
+jar2 = fetch.loadjar("session")
+assert.ne(tostring(jar2), tostring(jar))
+assert.eq(jar2:all(), jar:all())
+
+resp = fetch(TEST.url("/cookie/get"), {cookiejar=jar2})
+assert.eq(resp:read("*all"), "hello world")
+
+
+jar3 = fetch.loadjar(artifact.path .. "/session.json")
+assert.eq(jar3:all(), jar:all())
+
+
+ok = pcall(fetch.loadjar, "no-such-jar")
+assert.eq(ok, false)
@@ -0,0 +1,43 @@
+function pngsize(name)
+    local data = artifact.open(name, "rb"):read("*a")
+    assert.eq(data:sub(2, 4), "PNG")
+
+    local function u32(pos)
+        local a, b, c, d = data:byte(pos, pos + 3)
+        return ((a * 256 + b) * 256 + c) * 256 + d
+    end
+    return u32(17), u32(21)
+end
+
+
+t = tab.new({url=TEST.url(), width=400, height=300})
+t:eval("document.body.style.height = '2000px'")
+
+t:screenshot("viewport")
+assert.eq({pngsize("viewport.png")}, {400, 300})
+
+t:screenshot({name="full", fullpage=true})
+w, h = pngsize("full.png")
+assert.eq(w, 400)
+assert.eq(h >= 2000, true)
+
+t:screenshot({name="clip", clip={x=10, y=20, w=100, h=50}})
+assert.eq({pngsize("clip.png")}, {100, 50})
+
+t:screenshot({name="jpeg", format="jpeg", quality=50})
+assert.eq({artifact.open("jpeg.jpg", "rb"):read("*a"):byte(1, 2)}, {0xFF, 0xD8})
+
+t:screenshot({format="webp"})
+assert.eq(artifact.open("000001.webp", "rb"):read("*a"):sub(9, 12), "WEBP")
+
+t:screenshot(1)
+assert.eq({pngsize("1.png")}, {400, 300})
+
+t:screenshot({name="lowest", format="jpeg", quality=0})
+t:screenshot({name="highest", format="jpeg", quality=100})
+assert.eq(#artifact.open("lowest.jpg", "rb"):read("*a") < #artifact.open("highest.jpg", "rb"):read("*a"), true)
+
+
+ok, err = pcall(t.screenshot, t, {format="gif"})
+assert.eq(ok, false)
+assert.ne(err:find('unsupported format "gif"', 1, true), nil)
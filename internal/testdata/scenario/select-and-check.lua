@@ -0,0 +1,56 @@
+t = tab.new(TEST.url("/"))
+
+t:eval([[
+    document.body.insertAdjacentHTML('beforeend', `
+        <select id="color">
+            <option value="r">Red</option>
+            <option value="g">Green</option>
+            <option value="b">Blue</option>
+        </select>
+        <select id="sizes" multiple>
+            <option value="s">Small</option>
+            <option value="m">Medium</option>
+            <option value="l">Large</option>
+        </select>
+        <input id="agree" type="checkbox">
+        <input id="name" type="text">
+        <div id="nothing"></div>
+    `);
+
+    window.events = [];
+    for (const e of document.querySelectorAll('select, input')) {
+        e.addEventListener('input', (ev) => events.push('input:' + ev.target.id));
+        e.addEventListener('change', (ev) => events.push('change:' + ev.target.id));
+    }
+]])
+
+color = t("#color")
+color:select("g")
+assert.eq(color.value, "g")
+color:select("Blue")
+assert.eq(color.value, "b")
+assert.eq(t:eval("window.events"), {"input:color", "change:color", "input:color", "change:color"})
+
+t("#sizes"):select({"s", "Large"})
+assert.eq(t:eval("Array.from(document.querySelector('#sizes').selectedOptions).map(o => o.value)"), {"s", "l"})
+
+assert.eq(pcall(color.select, color, "purple"), false)
+assert.eq(pcall(color.select, color, {"r", "g"}), false)
+assert.eq(pcall(t("#nothing").select, t("#nothing"), "r"), false)
+
+t:eval("window.events = []")
+agree = t("#agree")
+agree:check()
+assert.eq(agree.checked, true)
+agree:check()
+assert.eq(agree.checked, true)
+agree:uncheck()
+assert.eq(agree.checked, false)
+assert.eq(t:eval("window.events"), {"input:agree", "change:agree", "input:agree", "change:agree"})
+
+assert.eq(pcall(t("#name").check, t("#name")), false)
+
+t:eval("window.events = []")
+t("#name"):setValue("alice")
+assert.eq(t("#name").value, "alice")
+assert.eq(t:eval("window.events"), {"input:name", "change:name"})
@@ -0,0 +1,38 @@
+t = tab.new(TEST.url("/"))
+
+t:eval([[
+    document.body.insertAdjacentHTML('beforeend', `
+        <my-input id="name"></my-input>
+        <my-input id="mail"></my-input>
+        <div id="closed"></div>
+    `);
+
+    for (const host of document.querySelectorAll('my-input')) {
+        const root = host.attachShadow({mode: 'open'});
+        root.innerHTML = '<label>' + host.id + '</label><input class="field"><my-inner></my-inner>';
+        root.querySelector('my-inner').attachShadow({mode: 'open'}).innerHTML = '<b>inner of ' + host.id + '</b>';
+    }
+
+    document.querySelector('#closed').attachShadow({mode: 'closed'}).innerHTML = '<span>secret</span>';
+]])
+
+assert.eq(pcall(t, "my-input input"), false)
+
+t("my-input >>> input"):sendKeys("alice")
+assert.eq(t("#name >>> input").value, "alice")
+assert.eq(#t:all("my-input >>> input"), 2)
+assert.eq(t("#mail >>> my-inner >>> b").text, "inner of mail")
+
+body = t("body")
+assert.eq(body("#mail >>> label").text, "mail")
+assert.eq(#body:all("my-input >>> .field"), 2)
+
+t:wait("#mail >>> input", 1000)
+
+root = t("#name").shadowRoot
+assert.eq(root("label").text, "name")
+assert.eq(#root:all("input"), 1)
+assert.eq(root("my-inner").shadowRoot("b").text, "inner of name")
+
+assert.eq(t("#closed").shadowRoot("span").text, "secret")
+assert.eq(t("body").shadowRoot, nil)
@@ -0,0 +1,74 @@
+t = tab.new(TEST.url("/"))
+
+t:eval([[
+    window.render = (label) => {
+        document.querySelector('#app').innerHTML = `
+            <div class="list"><span>first</span><span>${label}</span></div>
+            <input id="name">
+        `;
+    };
+    document.body.insertAdjacentHTML('beforeend', '<div id="app"></div>');
+    render('second');
+]])
+
+name = t("#name")
+items = t:all("#app span")
+list = t("#app .list")
+child = list("span")
+assert.eq(name.attached, true)
+
+t:eval("render('updated')")
+
+-- The old nodes are detached, but the elements are found again by the original queries.
+assert.eq(name.attached, false)
+name:sendKeys("hello")
+assert.eq(name.attached, true)
+assert.eq(t("#name").value, "hello")
+
+assert.eq(items[1].text, "first")
+assert.eq(child.text, "first")
+
+-- The element can't be found again if nothing matches to the query.
+t2 = tab.new({url=TEST.url("/"), timeout=200})
+t2:eval([[document.body.insertAdjacentHTML('beforeend', '<button id="gone">gone</button>')]])
+gone = t2("#gone")
+t2:eval("document.querySelector('#gone').remove()")
+
+assert.eq(gone.attached, false)
+ok, err = pcall(gone.click, gone)
+assert.eq(ok, false)
+assert.eq(err:match("element is detached") ~= nil, true)
+
+ok, err = pcall(function() return gone.text end)
+assert.eq(ok, false)
+assert.eq(err:match("element is detached") ~= nil, true)
+
+-- Elements in a list are identified by their content, not by the index.
+t2:eval([[
+    document.body.insertAdjacentHTML('beforeend', '<ul id="rows"></ul>');
+    window.renderRows = (rows) => {
+        document.querySelector('#rows').innerHTML = rows.map((r) => '<li onclick="this.innerText += \\'!\\'">' + r + '</li>').join('');
+    };
+    renderRows(['a', 'b', 'c']);
+]])
+rows = t2:all("#rows li")
+
+t2:eval("renderRows(['b', 'c'])")
+rows[2]:click()
+assert.eq(t2("#rows li").text, "b!")
+
+t2:eval("renderRows(['a', 'x', 'c'])")
+started = time.now()
+ok, err = pcall(rows[2].click, rows[2])
+assert.eq(ok, false)
+assert.eq(err:match("element is detached") ~= nil, true)
+assert.lt(time.now() - started, 200) -- It doesn't wait for the timeout if the content has been changed.
+assert.eq(rows[1].text, "a")
+assert.eq(rows[3].text, "c")
+
+-- It waits while the list is empty, because the list may be re-rendered.
+t2:eval([[
+    renderRows([]);
+    setTimeout(() => renderRows(['a', 'b', 'c']), 100);
+]])
+assert.eq(rows[1].text, "a")
@@ -0,0 +1,34 @@
+t = tab.new(TEST.url("/"))
+
+t:eval([[
+    document.body.insertAdjacentHTML('beforeend', `
+        <input id="single" type="file">
+        <input id="multi" type="file" multiple>
+        <div id="nothing"></div>
+    `);
+
+    window.uploaded = null;
+    document.querySelector('#single').addEventListener('change', async (ev) => {
+        window.uploaded = await ev.target.files[0].text();
+    });
+]])
+
+f = artifact.open("document.txt", "w")
+f:write("hello world")
+f:close()
+
+t("#single"):upload("document.txt")
+assert.eq(t:eval("document.querySelector('#single').files[0].name"), "document.txt")
+text = t:waitFunction("window.uploaded", 1000)
+assert.eq(text, "hello world")
+
+t("#multi"):upload("document.txt", "testdata/scenario/upload-file.lua")
+assert.eq(t:eval("Array.from(document.querySelector('#multi').files).map(f => f.name)"), {"document.txt", "upload-file.lua"})
+
+t("#single"):upload()
+assert.eq(t:eval("document.querySelector('#single').files.length"), 0)
+
+ok, err = pcall(t("#single").upload, t("#single"), "no-such-file.txt")
+assert.eq(ok, false)
+
+assert.eq(pcall(t("#nothing").upload, t("#nothing"), "document.txt"), false)
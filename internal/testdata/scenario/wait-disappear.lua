@@ -0,0 +1,35 @@
+t = tab.new(TEST.url("/"))
+
+t:eval([[
+    document.body.insertAdjacentHTML('beforeend', '<div id="overlay">loading</div><div id="spinner">loading</div><div id="removed">loading</div>');
+    setTimeout(() => document.querySelector('#overlay').remove(), 200);
+    setTimeout(() => document.querySelector('#spinner').style.display = 'none', 200);
+    setTimeout(() => document.querySelector('#removed').remove(), 200);
+]])
+
+t:waitGone("#overlay")
+assert.eq(#t:all("#overlay"), 0)
+
+t:waitHidden("#spinner")
+assert.eq(t:eval("document.querySelector('#spinner').style.display"), "none")
+
+t:waitHidden("#removed")
+
+
+t:eval([[
+    document.body.insertAdjacentHTML('beforeend', '<p class="xp">loading</p><p class="xp2">loading</p>');
+    setTimeout(() => document.querySelector('.xp').remove(), 200);
+    setTimeout(() => document.querySelector('.xp2').style.visibility = 'hidden', 200);
+]])
+
+t:waitXPathGone("//p[@class='xp']")
+t:waitXPathHidden("//p[@class='xp2']")
+
+
+ok, err = pcall(t.waitGone, t, "#greeting", 100)
+assert.eq(ok, false)
+assert.eq(err, "testdata/scenario/wait-disappear.lua:28: timeout")
+
+ok, err = pcall(t.waitHidden, t, "#greeting", 100)
+assert.eq(ok, false)
+assert.eq(err, "testdata/scenario/wait-disappear.lua:32: timeout")
@@ -0,0 +1,14 @@
+t = tab.new(TEST.url("/dynamic"))
+
+t:eval([[
+    window.store = {ready: false, items: []};
+    setTimeout(() => { store.items.push('a', 'b'); store.ready = true }, 200);
+]])
+
+assert.eq(t:waitFunction("store.ready && store.items.length"), 2)
+assert.eq(t:waitFunction("store.ready && store.items", 1000, 50), {"a", "b"})
+
+
+ok, err = pcall(t.waitFunction, t, "false", 100)
+assert.eq(ok, false)
+assert.eq(err, "testdata/scenario/wait-function.lua:11: timeout")
@@ -0,0 +1,38 @@
+t = tab.new(TEST.url("/navigation"))
+
+t:waitLoad("networkidle")
+assert.eq(t("#fetched").text, "ok")
+
+
+t("#link"):click({waitNavigation=true})
+assert.eq(t.url, TEST.url("/?target=next"))
+assert.eq(t("b").text, "next")
+
+
+t:back()
+
+t("#delayed"):click()
+t:waitNavigation()
+assert.eq(t.url, TEST.url("/?target=delayed"))
+t:waitLoad("domcontentloaded")
+t:waitLoad("load")
+
+
+t:back()
+
+t("#hash"):click()
+t:waitNavigation(1000)
+assert.eq(t.url, TEST.url("/navigation#hash"))
+
+
+ok, err = pcall(t.waitNavigation, t, 100)
+assert.eq(ok, false)
+assert.eq(err, "testdata/scenario/wait-navigation.lua:30: timeout")
+
+ok, err = pcall(function() t("#fetched"):click({waitNavigation=true, timeout=100}) end)
+assert.eq(ok, false)
+assert.ne(err:find("navigation did not happen within 100ms after click", 1, true), nil)
+
+ok, err = pcall(t.waitLoad, t, "idle")
+assert.eq(ok, false)
+assert.ne(err:find('unknown state "idle"', 1, true), nil)
@@ -0,0 +1,120 @@
+package webscenario
+
+import (
+	"github.com/chromedp/cdproto/cdp"
+	"github.com/chromedp/cdproto/network"
+	"github.com/yuin/gopher-lua"
+)
+
+// ResourceTimings is a breakdown of the time that spent for a request in milliseconds.
+// Each value is -1 if it is not applicable to the request.
+type ResourceTimings struct {
+	Blocked float64
+	DNS     float64
+	Connect float64
+	SSL     float64
+	Send    float64
+	Wait    float64
+	Receive float64
+}
+
+func span(start, end float64) float64 {
+	if start < 0 || end < 0 {
+		return -1
+	}
+	return end - start
+}
+
+// CalcResourceTimings makes timings breakdown from network.ResourceTiming.
+// The finished is the time when loading finished in seconds in the same clock as timing.RequestTime, or 0 if unknown.
+func CalcResourceTimings(timing *network.ResourceTiming, finished float64) ResourceTimings {
+	if timing == nil {
+		return ResourceTimings{-1, -1, -1, -1, -1, -1, -1}
+	}
+
+	rt := ResourceTimings{
+		DNS:     span(timing.DNSStart, timing.DNSEnd),
+		Connect: span(timing.ConnectStart, timing.ConnectEnd),
+		SSL:     span(timing.SslStart, timing.SslEnd),
+		Send:    span(timing.SendStart, timing.SendEnd),
+		Wait:    span(timing.SendEnd, timing.ReceiveHeadersEnd),
+		Receive: -1,
+	}
+
+	rt.Blocked = timing.SendStart
+	for _, x := range []float64{timing.ConnectStart, timing.DNSStart} {
+		if x >= 0 {
+			rt.Blocked = x
+		}
+	}
+	if rt.Blocked < 0 {
+		rt.Blocked = -1
+	}
+
+	if finished > 0 {
+		rt.Receive = span(timing.ReceiveHeadersEnd, (finished-timing.RequestTime)*1000)
+	}
+
+	return rt
+}
+
+// Total returns sum of the timings in milliseconds.
+func (rt ResourceTimings) Total() float64 {
+	total := 0.0
+	// SSL is not counted because it is included in connect.
+	for _, x := range []float64{rt.Blocked, rt.DNS, rt.Connect, rt.Send, rt.Wait, rt.Receive} {
+		if x > 0 {
+			total += x
+		}
+	}
+	return total
+}
+
+func monotonicSeconds(t *cdp.MonotonicTime) float64 {
+	if t == nil {
+		return 0
+	}
+	return t.Time().Sub(*cdp.MonotonicTimeEpoch).Seconds()
+}
+
+func nonNegative(x float64) float64 {
+	if x < 0 {
+		return 0
+	}
+	return x
+}
+
+// PackResourceTimings makes a Lua table of timings until the response headers received.
+// Receive time is not included because the response event occurs before receiving the body.
+func PackResourceTimings(L *lua.LState, rt ResourceTimings) *lua.LTable {
+	tbl := L.NewTable()
+	L.SetField(tbl, "dns", lua.LNumber(rt.DNS))
+	L.SetField(tbl, "connect", lua.LNumber(rt.Connect))
+	L.SetField(tbl, "tls", lua.LNumber(rt.SSL))
+	L.SetField(tbl, "send", lua.LNumber(rt.Send))
+	L.SetField(tbl, "wait", lua.LNumber(rt.Wait))
+	return tbl
+}
+
+// PackSecurityDetails makes a Lua table of TLS connection and certificate information.
+func PackSecurityDetails(L *lua.LState, d *network.SecurityDetails) *lua.LTable {
+	tbl := L.NewTable()
+	L.SetField(tbl, "protocol", lua.LString(d.Protocol))
+	L.SetField(tbl, "cipher", lua.LString(d.Cipher))
+	L.SetField(tbl, "subject", lua.LString(d.SubjectName))
+	L.SetField(tbl, "issuer", lua.LString(d.Issuer))
+
+	san := L.NewTable()
+	for _, x := range d.SanList {
+		san.Append(lua.LString(x))
+	}
+	L.SetField(tbl, "sanList", san)
+
+	if d.ValidFrom != nil {
+		L.SetField(tbl, "validFrom", lua.LNumber(d.ValidFrom.Time().UnixMilli()))
+	}
+	if d.ValidTo != nil {
+		L.SetField(tbl, "validTo", lua.LNumber(d.ValidTo.Time().UnixMilli()))
+	}
+	return tbl
+}
@@ -0,0 +1,78 @@
+package webscenario
+
+import (
+	"testing"
+
+	"github.com/chromedp/cdproto/network"
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestCalcResourceTimings(t *testing.T) {
+	tests := []struct {
+		Name     string
+		Timing   *network.ResourceTiming
+		Finished float64
+		Want     ResourceTimings
+		Total    float64
+	}{
+		{
+			"new-connection",
+			&network.ResourceTiming{
+				RequestTime:       100,
+				DNSStart:          1,
+				DNSEnd:            3,
+				ConnectStart:      3,
+				ConnectEnd:        10,
+				SslStart:          5,
+				SslEnd:            10,
+				SendStart:         11,
+				SendEnd:           12,
+				ReceiveHeadersEnd: 50,
+			},
+			100.07,
+			ResourceTimings{Blocked: 1, DNS: 2, Connect: 7, SSL: 5, Send: 1, Wait: 38, Receive: 20},
+			69,
+		},
+		{
+			"reused-connection",
+			&network.ResourceTiming{
+				RequestTime:       100,
+				DNSStart:          -1,
+				DNSEnd:            -1,
+				ConnectStart:      -1,
+				ConnectEnd:        -1,
+				SslStart:          -1,
+				SslEnd:            -1,
+				SendStart:         2,
+				SendEnd:           3,
+				ReceiveHeadersEnd: 10,
+			},
+			0,
+			ResourceTimings{Blocked: 2, DNS: -1, Connect: -1, SSL: -1, Send: 1, Wait: 7, Receive: -1},
+			10,
+		},
+		{
+			"no-timing",
+			nil,
+			0,
+			ResourceTimings{Blocked: -1, DNS: -1, Connect: -1, SSL: -1, Send: -1, Wait: -1, Receive: -1},
+			0,
+		},
+	}
+
+	round := cmp.Transformer("round", func(f float64) float64 {
+		return float64(int64(f*1000+0.5)) / 1000
+	})
+
+	for _, tt := range tests {
+		t.Run(tt.Name, func(t *testing.T) {
+			actual := CalcResourceTimings(tt.Timing, tt.Finished)
+			if diff := cmp.Diff(tt.Want, actual, round); diff != "" {
+				t.Error(diff)
+			}
+			if diff := cmp.Diff(tt.Total, actual.Total(), round); diff != "" {
+				t.Errorf("total: %s", diff)
+			}
+		})
+	}
+}
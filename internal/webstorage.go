@@ -0,0 +1,130 @@
+package webscenario
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/chromedp/cdproto/domstorage"
+	"github.com/chromedp/chromedp"
+	"github.com/yuin/gopher-lua"
+)
+
+// WebStorage is an accessor to localStorage or sessionStorage of the page that opened in a tab.
+type WebStorage struct {
+	tab   *Tab
+	local bool
+}
+
+func (s WebStorage) String() string {
+	if s.local {
+		return "$.localStorage"
+	}
+	return "$.sessionStorage"
+}
+
+func (s WebStorage) withID(f func(ctx context.Context, id *domstorage.StorageID) error) chromedp.Action {
+	return chromedp.ActionFunc(func(ctx context.Context) error {
+		var origin string
+		if err := chromedp.Evaluate("location.origin", &origin).Do(ctx); err != nil {
+			return err
+		}
+		if origin == "null" {
+			return fmt.Errorf("%s is not available on this page", s)
+		}
+		return f(ctx, &domstorage.StorageID{
+			SecurityOrigin: origin,
+			IsLocalStorage: s.local,
+		})
+	})
+}
+
+func (s WebStorage) Get(L *lua.LState) int {
+	key := L.CheckString(2)
+
+	var value string
+	var ok bool
+	s.tab.Run(L, fmt.Sprintf("%s[%q]", s, key), false, 0, s.withID(func(ctx context.Context, id *domstorage.StorageID) error {
+		items, err := domstorage.GetDOMStorageItems(id).Do(ctx)
+		for _, item := range items {
+			if len(item) == 2 && item[0] == key {
+				value, ok = item[1], true
+				break
+			}
+		}
+		return err
+	}))
+
+	if ok {
+		L.Push(lua.LString(value))
+		return 1
+	} else {
+		return 0
+	}
+}
+
+func (s WebStorage) Set(L *lua.LState) int {
+	key := L.CheckString(2)
+	value := L.Get(3)
+
+	if value.Type() == lua.LTNil {
+		s.tab.Run(L, fmt.Sprintf("%s[%q] = nil", s, key), false, 0, s.withID(func(ctx context.Context, id *domstorage.StorageID) error {
+			return domstorage.RemoveDOMStorageItem(id, key).Do(ctx)
+		}))
+	} else {
+		v := L.ToStringMeta(value).String()
+		s.tab.Run(L, fmt.Sprintf("%s[%q] = %q", s, key, v), false, 0, s.withID(func(ctx context.Context, id *domstorage.StorageID) error {
+			return domstorage.SetDOMStorageItem(id, key, v).Do(ctx)
+		}))
+	}
+
+	return 0
+}
+
+func (s WebStorage) All(L *lua.LState) int {
+	var items []domstorage.Item
+	s.tab.Run(L, fmt.Sprintf("%s()", s), false, 0, s.withID(func(ctx context.Context, id *domstorage.StorageID) (err error) {
+		items, err = domstorage.GetDOMStorageItems(id).Do(ctx)
+		return err
+	}))
+
+	tbl := L.NewTable()
+	for _, item := range items {
+		if len(item) == 2 {
+			L.SetField(tbl, item[0], lua.LString(item[1]))
+		}
+	}
+	L.Push(tbl)
+	return 1
+}
+
+func (s WebStorage) ToLua(L *lua.LState) *lua.LUserData {
+	check := func(L *lua.LState) WebStorage {
+		if ud, ok := L.Get(1).(*lua.LUserData); ok {
+			if s, ok := ud.Value.(WebStorage); ok {
+				return s
+			}
+		}
+		L.ArgError(1, "storage expected.")
+		return WebStorage{}
+	}
+
+	ud := L.NewUserData()
+	ud.Value = s
+	ud.Metatable = L.SetFuncs(L.NewTable(), map[string]lua.LGFunction{
+		"__index": func(L *lua.LState) int {
+			return check(L).Get(L)
+		},
+		"__newindex": func(L *lua.LState) int {
+			return check(L).Set(L)
+		},
+		"__call": func(L *lua.LState) int {
+			return check(L).All(L)
+		},
+		"__tostring": func(L *lua.LState) int {
+			L.Push(lua.LString(fmt.Sprintf("tab#%d%s", s.tab.id, strings.TrimPrefix(s.String(), "$"))))
+			return 1
+		},
+	})
+	return ud
+}
@@ -62,6 +62,7 @@ func main() {
 	flags.BoolVar(&arg.Debug, "debug", false, "enable debug mode.")
 	flags.BoolVar(&arg.Head, "head", false, "show browser window while execution.")
 	flags.BoolVar(&arg.Recording, "gif", false, "enable recording animation gif.")
+	flags.DurationVar(&arg.StepTimeout, "step-timeout", webscenario.DefaultStepTimeout, "default timeout to wait for elements in each step.")
 	showVersion := flags.BoolP("version", "v", false, "show version and exit.")
 	showHelp := flags.BoolP("help", "h", false, "show help message and exit.")
 